@@ -4,6 +4,7 @@ go 1.13
 
 require (
 	github.com/go-resty/resty/v2 v2.5.0
+	github.com/hashicorp/go-cty v1.4.1-0.20200414143053-d3edf31b6320
 	github.com/hashicorp/terraform-plugin-sdk/v2 v2.0.1
 	github.com/tidwall/gjson v1.5.0
 )
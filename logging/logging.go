@@ -0,0 +1,142 @@
+// Package logging provides the leveled, redacting logger shared by the
+// provider and the wugapi client.
+//
+// Lines are written through the standard log package with a Terraform
+// level prefix, so that TF_LOG filtering applies:
+//
+//	[DEBUG] [WUG] [client] message
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+)
+
+// Level is a log severity understood by Terraform.
+type Level string
+
+// Log levels, from the most to the least verbose.
+const (
+	Trace Level = "TRACE"
+	Debug Level = "DEBUG"
+	Info  Level = "INFO"
+	Warn  Level = "WARN"
+	Error Level = "ERROR"
+)
+
+// Redacted replaces sensitive values in log output.
+const Redacted = "<redacted>"
+
+/* Keys whose values never end up in logs, in JSON bodies as well as in
+ * form-encoded payloads: the words below, alone or after a prefix such as
+ * refresh_token or authPassword. Keys merely starting with them, such as
+ * credentialType or token_type, are left alone. */
+const sensitiveWords = `password|passwd|secret|token|passphrase|community|private_?key|client_?key|api_?key`
+
+var sensitiveKey = regexp.MustCompile(`(?i)^[a-z0-9]*_?(?:` + sensitiveWords + `|authorization)$`)
+
+var redactions = []struct {
+	re   *regexp.Regexp
+	repl string
+}{
+	{
+		re:   regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9\-._~+/]+=*`),
+		repl: "${1}" + Redacted,
+	},
+	{
+		re:   regexp.MustCompile(`(?i)(\b[a-z0-9]*_?(?:` + sensitiveWords + `)"?\s*[:=]\s*"?)[^"&\s,}]*`),
+		repl: "${1}" + Redacted,
+	},
+}
+
+// Logger writes leveled lines for one subsystem of the provider.
+type Logger struct {
+	subsystem string
+}
+
+// New returns a Logger tagged with the given subsystem.
+func New(subsystem string) *Logger {
+	return &Logger{subsystem: subsystem}
+}
+
+// Logf writes a redacted line at the given level.
+func (l *Logger) Logf(level Level, format string, args ...interface{}) {
+	log.Printf("[%s] [WUG] [%s] %s", level, l.subsystem, RedactString(fmt.Sprintf(format, args...)))
+}
+
+// Tracef logs at TRACE level.
+func (l *Logger) Tracef(format string, args ...interface{}) {
+	l.Logf(Trace, format, args...)
+}
+
+// Debugf logs at DEBUG level.
+func (l *Logger) Debugf(format string, args ...interface{}) {
+	l.Logf(Debug, format, args...)
+}
+
+// Infof logs at INFO level.
+func (l *Logger) Infof(format string, args ...interface{}) {
+	l.Logf(Info, format, args...)
+}
+
+// Warnf logs at WARN level.
+func (l *Logger) Warnf(format string, args ...interface{}) {
+	l.Logf(Warn, format, args...)
+}
+
+// Errorf logs at ERROR level.
+func (l *Logger) Errorf(format string, args ...interface{}) {
+	l.Logf(Error, format, args...)
+}
+
+// RedactString masks bearer tokens and key=value or "key": "value" pairs
+// whose key looks sensitive.
+func RedactString(s string) string {
+	for _, r := range redactions {
+		s = r.re.ReplaceAllString(s, r.repl)
+	}
+
+	return s
+}
+
+// Redact returns a printable copy of a payload with sensitive fields masked.
+// JSON documents are walked so that nested fields are covered too.
+func Redact(body []byte) string {
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return RedactString(string(body))
+	}
+
+	/* Without HTML escaping, for Redacted to read as is. */
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(redactValue(value)); err != nil {
+		return RedactString(string(body))
+	}
+
+	return strings.TrimSuffix(out.String(), "\n")
+}
+
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if sensitiveKey.MatchString(key) {
+				v[key] = Redacted
+			} else {
+				v[key] = redactValue(item)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactValue(item)
+		}
+	}
+
+	return value
+}
@@ -1,70 +1,30 @@
 package wug
 
 import (
-	"errors"
-	"log"
-	"net/url"
+	"time"
 
-	"github.com/go-resty/resty/v2"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"github.com/tidwall/gjson"
-)
-
-// Client holds the Resty instance and API configuration.
-type Client struct {
-	Resty  *resty.Client
-	Config *Config
-	Token  string
-}
 
-// Config holds API configuration parameters.
-type Config struct {
-	InsecureFlag bool
-	User         string
-	Password     string
-	URL          string
-}
+	"github.com/nerimcloud/terraform-provider-wug/wugapi"
+)
 
 // NewConfig instanciates a Config object.
-func NewConfig(d *schema.ResourceData) (*Config, error) {
-	c := &Config{
+func NewConfig(d *schema.ResourceData) (*wugapi.Config, error) {
+	c := &wugapi.Config{
 		User:         d.Get("user").(string),
 		Password:     d.Get("password").(string),
 		InsecureFlag: d.Get("allow_unverified_ssl").(bool),
 		URL:          d.Get("url").(string),
-	}
-
-	return c, nil
-}
-
-// Client returns a REST client for WUG.
-func (c *Config) Client() (*Client, error) {
-	client := new(Client)
-
-	client.Resty = resty.New()
-
-	params := url.Values{}
-	params.Add("grant_type", "password")
-	params.Add("username", c.User)
-	params.Add("password", c.Password)
 
-	resp, err := client.Resty.R().
-		SetHeader("Content-Type", "application/json").
-		//SetBody(map[string]interface{}{"grant_type": "password", "username": c.User, "password": c.Password}).
-		SetBody(params.Encode()).
-		Post(c.URL + "/token")
+		CACertFile:    d.Get("ca_cert_file").(string),
+		CACertPEM:     d.Get("ca_cert_pem").(string),
+		ClientCert:    d.Get("client_cert").(string),
+		ClientKey:     d.Get("client_key").(string),
+		TLSServerName: d.Get("tls_server_name").(string),
 
-	if err != nil {
-		return nil, err
-	} else if resp.StatusCode() != 200 {
-		return nil, errors.New(string(resp.Body()))
+		MaxRetries:   d.Get("max_retries").(int),
+		RetryMaxWait: time.Duration(d.Get("retry_max_wait").(int)) * time.Second,
 	}
 
-	client.Token = gjson.GetBytes(resp.Body(), "access_token").String()
-
-	log.Printf("[WUG] Access token: %s", client.Token)
-
-	client.Config = c
-
-	return client, nil
+	return c, nil
 }
@@ -0,0 +1,158 @@
+package wug
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/nerimcloud/terraform-provider-wug/wugapi"
+)
+
+var deviceLookups = []string{"name", "network_address", "hostname"}
+
+func dataSourceDevice() *schema.Resource {
+	attributes := dataSourceDeviceSchema()
+
+	attributes["name"].Description = "Display name of the device to look for."
+	attributes["network_address"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Description: "Network address of the default interface of the device to look for.",
+	}
+	attributes["hostname"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Description: "Host name of the default interface of the device to look for.",
+	}
+
+	for _, lookup := range deviceLookups {
+		attributes[lookup].Optional = true
+		attributes[lookup].Computed = true
+		attributes[lookup].ExactlyOneOf = deviceLookups
+	}
+
+	return &schema.Resource{
+		ReadContext: dataSourceDeviceRead,
+
+		Timeouts: &schema.ResourceTimeout{
+			Read: schema.DefaultTimeout(5 * time.Minute),
+		},
+
+		Schema: attributes,
+	}
+}
+
+// dataSourceDeviceSchema is the schema of wug_device without the arguments
+// only used on creation, every attribute being computed. Sets become lists,
+// in the order of WUG.
+func dataSourceDeviceSchema() map[string]*schema.Schema {
+	attributes := resourceDevice().Schema
+	delete(attributes, "options")
+	delete(attributes, "template_json")
+	delete(attributes, "rendered_template_json")
+
+	var compute func(map[string]*schema.Schema)
+	compute = func(attributes map[string]*schema.Schema) {
+		for _, attribute := range attributes {
+			*attribute = schema.Schema{
+				Type:        attribute.Type,
+				Description: attribute.Description,
+				Computed:    true,
+				Elem:        attribute.Elem,
+			}
+			if attribute.Type == schema.TypeSet {
+				attribute.Type = schema.TypeList
+			}
+			if elem, ok := attribute.Elem.(*schema.Resource); ok {
+				compute(elem.Schema)
+			}
+		}
+	}
+	compute(attributes)
+
+	return attributes
+}
+
+// deviceSummaryField returns the field of a device summary matching a lookup
+// attribute of the data source.
+func deviceSummaryField(device wugapi.DeviceSummary, attribute string) string {
+	switch attribute {
+	case "network_address":
+		return device.NetworkAddress
+	case "hostname":
+		return device.HostName
+	}
+
+	return device.Name
+}
+
+// searchDevice returns the only device whose attribute is wanted. The search
+// matches substrings of any field, keep the exact matches on the attribute.
+func searchDevice(ctx context.Context, client *wugapi.Client, attribute, wanted string) (wugapi.DeviceSummary, error) {
+	devices, err := client.SearchDevices(ctx, wanted)
+	if err != nil {
+		return wugapi.DeviceSummary{}, err
+	}
+
+	found := make([]wugapi.DeviceSummary, 0)
+	for _, device := range devices {
+		/* Host names are not case sensitive. */
+		if field := deviceSummaryField(device, attribute); field == wanted || (attribute == "hostname" && strings.EqualFold(field, wanted)) {
+			found = append(found, device)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return wugapi.DeviceSummary{}, fmt.Errorf("device %q: %w", wanted, wugapi.ErrNotFound)
+	case 1:
+		return found[0], nil
+	}
+
+	ids := make([]string, 0, len(found))
+	for _, device := range found {
+		ids = append(ids, device.ID)
+	}
+
+	return wugapi.DeviceSummary{}, fmt.Errorf("%d devices match %q: %s", len(found), wanted, strings.Join(ids, ", "))
+}
+
+func dataSourceDeviceRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*wugapi.Client)
+
+	var attribute, wanted string
+	for _, lookup := range deviceLookups {
+		if value, ok := d.GetOk(lookup); ok {
+			attribute, wanted = lookup, value.(string)
+		}
+	}
+
+	device, err := searchDevice(ctx, client, attribute, wanted)
+	if errors.Is(err, wugapi.ErrNotFound) {
+		return diag.Diagnostics{
+			diag.Diagnostic{
+				Severity:      diag.Error,
+				Summary:       "Found no device for " + wanted,
+				AttributePath: cty.GetAttrPath(attribute),
+			},
+		}
+	} else if err != nil {
+		return errorDiag(ctx, "Unable to find the device", err, cty.GetAttrPath(attribute))
+	}
+
+	template, err := client.GetDeviceTemplate(ctx, device.ID)
+	if err != nil {
+		return errorDiag(ctx, "Unable to read device "+device.ID, err, nil)
+	}
+
+	flattenDeviceTemplate(d, template)
+	d.Set("network_address", device.NetworkAddress)
+	d.Set("hostname", device.HostName)
+	d.SetId(device.ID)
+
+	return nil
+}
@@ -0,0 +1,157 @@
+package wug
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/nerimcloud/terraform-provider-wug/wugapi"
+)
+
+func dataSourceDeviceGroup() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceDeviceGroupRead,
+
+		Timeouts: &schema.ResourceTimeout{
+			Read: schema.DefaultTimeout(5 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"path": {
+				Type:         schema.TypeList,
+				Description:  "Names of the group and its ancestors, from the top-level group down.",
+				Optional:     true,
+				ExactlyOneOf: []string{"path", "search"},
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"search": {
+				Type:         schema.TypeString,
+				Description:  "Name of the group to look for, it must match a single group.",
+				Optional:     true,
+				ExactlyOneOf: []string{"path", "search"},
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Description: "Name of the group.",
+				Computed:    true,
+			},
+			"full_path": {
+				Type:        schema.TypeList,
+				Description: "Names of the group and its ancestors, from the top-level group down.",
+				Computed:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"parent_id": {
+				Type:        schema.TypeString,
+				Description: "ID of the parent group.",
+				Computed:    true,
+			},
+			"description": {
+				Type:        schema.TypeString,
+				Description: "Description of the group.",
+				Computed:    true,
+			},
+			"member_count": {
+				Type:        schema.TypeInt,
+				Description: "Number of devices in the group.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+// searchDeviceGroup returns the only group matching search. When several
+// groups match, an exact name match wins.
+func searchDeviceGroup(ctx context.Context, client *wugapi.Client, tree wugapi.DeviceGroupTree, search string) (wugapi.DeviceGroup, error) {
+	groups, err := client.ListDeviceGroups(ctx, search)
+	if err != nil {
+		return wugapi.DeviceGroup{}, err
+	}
+
+	if len(groups) > 1 {
+		exact := make([]wugapi.DeviceGroup, 0)
+		for _, group := range groups {
+			if group.Name == search {
+				exact = append(exact, group)
+			}
+		}
+		if len(exact) > 0 {
+			groups = exact
+		}
+	}
+
+	switch len(groups) {
+	case 0:
+		return wugapi.DeviceGroup{}, fmt.Errorf("device group %q: %w", search, wugapi.ErrNotFound)
+	case 1:
+		return groups[0], nil
+	}
+
+	paths := make([]string, 0, len(groups))
+	for _, group := range groups {
+		paths = append(paths, strings.Join(tree.Path(group.ID), "/"))
+	}
+
+	return wugapi.DeviceGroup{}, fmt.Errorf("%d device groups match %q, use path instead: %s", len(groups), search, strings.Join(paths, ", "))
+}
+
+func dataSourceDeviceGroupRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*wugapi.Client)
+
+	tree, err := client.GetDeviceGroupTree(ctx)
+	if err != nil {
+		return errorDiag(ctx, "Unable to list device groups", err, nil)
+	}
+
+	var group wugapi.DeviceGroup
+	var attribute, wanted string
+
+	if search, ok := d.GetOk("search"); ok {
+		attribute, wanted = "search", search.(string)
+		group, err = searchDeviceGroup(ctx, client, tree, wanted)
+	} else {
+		path := make([]string, 0)
+		for _, name := range d.Get("path").([]interface{}) {
+			path = append(path, name.(string))
+		}
+
+		attribute, wanted = "path", strings.Join(path, "/")
+		group, err = tree.Find(path)
+	}
+
+	if errors.Is(err, wugapi.ErrNotFound) {
+		return diag.Diagnostics{
+			diag.Diagnostic{
+				Severity:      diag.Error,
+				Summary:       "Found no device group for " + wanted,
+				AttributePath: cty.GetAttrPath(attribute),
+			},
+		}
+	} else if err != nil {
+		return errorDiag(ctx, "Unable to find the device group", err, cty.GetAttrPath(attribute))
+	}
+
+	devices, err := client.ListDeviceGroupDevices(ctx, group.ID)
+	if err != nil {
+		return errorDiag(ctx, "Unable to list the devices of group "+group.ID, err, nil)
+	}
+
+	d.Set("name", group.Name)
+	d.Set("full_path", tree.Path(group.ID))
+	d.Set("parent_id", group.ParentGroupID)
+	d.Set("description", group.Description)
+	d.Set("member_count", len(devices))
+	d.SetId(group.ID)
+
+	return nil
+}
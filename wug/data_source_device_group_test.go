@@ -0,0 +1,107 @@
+package wug
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+
+	"github.com/nerimcloud/terraform-provider-wug/wugapi/wugtest"
+)
+
+/* testAccDeviceGroupTree seeds My Network/Datacenter/Linux with a device,
+ * and a second Linux group under My Network/Lab. */
+func testAccDeviceGroupTree(srv *wugtest.Server) (linuxID string) {
+	datacenterID := srv.AddDeviceGroup(wugtest.RootGroupID, "Datacenter")
+	linuxID = srv.AddDeviceGroup(datacenterID, "Linux")
+	labID := srv.AddDeviceGroup(wugtest.RootGroupID, "Lab")
+	srv.AddDeviceGroup(labID, "Linux")
+
+	srv.AddDevice(wugtest.Object{
+		"displayName": "web-01",
+		"groups": []interface{}{
+			wugtest.Object{"name": "Linux", "parents": []interface{}{wugtest.RootGroupName, "Datacenter"}},
+		},
+	})
+
+	return linuxID
+}
+
+func TestAccDataSourceDeviceGroup_path(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	linuxID := testAccDeviceGroupTree(srv)
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceDeviceGroupConfig(srv, fmt.Sprintf("path = [%q, \"Datacenter\", \"Linux\"]", wugtest.RootGroupName)),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.wug_device_group.test", "id", linuxID),
+					resource.TestCheckResourceAttr("data.wug_device_group.test", "name", "Linux"),
+					resource.TestCheckResourceAttr("data.wug_device_group.test", "full_path.#", "3"),
+					resource.TestCheckResourceAttr("data.wug_device_group.test", "full_path.1", "Datacenter"),
+					resource.TestCheckResourceAttr("data.wug_device_group.test", "member_count", "1"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccDataSourceDeviceGroup_search(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	testAccDeviceGroupTree(srv)
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceDeviceGroupConfig(srv, `search = "Datacenter"`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.wug_device_group.test", "name", "Datacenter"),
+					resource.TestCheckResourceAttr("data.wug_device_group.test", "parent_id", wugtest.RootGroupID),
+					resource.TestCheckResourceAttr("data.wug_device_group.test", "member_count", "0"),
+				),
+			},
+			{
+				Config:      testAccDataSourceDeviceGroupConfig(srv, `search = "Linux"`),
+				ExpectError: regexp.MustCompile(`2 device groups match "Linux"`),
+			},
+		},
+	})
+}
+
+func TestAccDataSourceDeviceGroup_notFound(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	testAccDeviceGroupTree(srv)
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				/* A typo in the path must not resolve to another group. */
+				Config:      testAccDataSourceDeviceGroupConfig(srv, fmt.Sprintf("path = [%q, \"Datacentre\", \"Linux\"]", wugtest.RootGroupName)),
+				ExpectError: regexp.MustCompile("Found no device group for My Network/Datacentre/Linux"),
+			},
+			{
+				Config:      testAccDataSourceDeviceGroupConfig(srv, `search = "Windows"`),
+				ExpectError: regexp.MustCompile("Found no device group for Windows"),
+			},
+		},
+	})
+}
+
+func testAccDataSourceDeviceGroupConfig(srv *wugtest.Server, lookup string) string {
+	return testAccProviderConfig(srv) + fmt.Sprintf(`
+data "wug_device_group" "test" {
+  %s
+}
+`, lookup)
+}
@@ -0,0 +1,299 @@
+package wug
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	"github.com/nerimcloud/terraform-provider-wug/wugapi"
+)
+
+// typedTemplateFields are the template fields wug_device has arguments for.
+// Interfaces are handled apart, as they may carry more properties.
+var typedTemplateFields = []string{
+	"templateId",
+	"displayName",
+	"groups",
+	"credentials",
+	"activeMonitors",
+	"performanceMonitors",
+	"deviceType",
+	"snmpOid",
+	"primaryRole",
+	"subRoles",
+	"os",
+	"brand",
+	"actionPolicy",
+}
+
+// typedInterfaceFields are the interface properties wug_device has arguments
+// for.
+var typedInterfaceFields = map[string]bool{
+	"defaultInterface":     true,
+	"pollUsingNetworkName": true,
+	"networkAddress":       true,
+	"networkName":          true,
+}
+
+func dataSourceDeviceTemplate() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceDeviceTemplateRead,
+
+		Timeouts: &schema.ResourceTimeout{
+			Read: schema.DefaultTimeout(5 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"device_id": {
+				Type:        schema.TypeString,
+				Description: "ID of the device to export.",
+				Required:    true,
+			},
+			"resource_name": {
+				Type:         schema.TypeString,
+				Description:  "Name of the wug_device resource in the HCL snippet.",
+				Optional:     true,
+				Default:      "device",
+				ValidateFunc: validation.StringMatch(regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`), "must be a valid Terraform identifier"),
+			},
+			"options": {
+				Type:        schema.TypeString,
+				Description: "Set of options written in the HCL snippet (either l2 or basic).",
+				Optional:    true,
+				Default:     "basic",
+				ValidateFunc: validation.StringInSlice([]string{
+					"l2",
+					"basic",
+				}, true),
+			},
+			"template_json": {
+				Type:        schema.TypeString,
+				Description: "Template of the device, as sent by WUG.",
+				Computed:    true,
+			},
+			"hcl": {
+				Type:        schema.TypeString,
+				Description: "wug_device resource creating a copy of the device.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func dataSourceDeviceTemplateRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*wugapi.Client)
+
+	deviceID := d.Get("device_id").(string)
+
+	raw, err := client.GetDeviceTemplateJSON(ctx, deviceID)
+	if err != nil {
+		return errorDiag(ctx, "Unable to read device "+deviceID, err, faultPath(err, cty.GetAttrPath("device_id")))
+	}
+
+	var template wugapi.DeviceTemplate
+	if err := json.Unmarshal(raw, &template); err != nil {
+		return errorDiag(ctx, "Unable to read device "+deviceID, err, nil)
+	}
+
+	extra, err := untypedTemplateFields(raw)
+	if err != nil {
+		return errorDiag(ctx, "Unable to read device "+deviceID, err, nil)
+	}
+
+	d.Set("template_json", string(raw))
+	d.Set("hcl", deviceTemplateHCL(d.Get("resource_name").(string), d.Get("options").(string), &template, extra))
+	d.SetId(deviceID)
+
+	return nil
+}
+
+// untypedTemplateFields returns the fields of a template that wug_device has
+// no argument for, to be passed as its template_json. Interfaces are kept
+// whole when any of them has such a property, template_json merging them.
+func untypedTemplateFields(raw []byte) (map[string]interface{}, error) {
+	/* Numbers are kept as written. */
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	var fields map[string]interface{}
+	if err := decoder.Decode(&fields); err != nil {
+		return nil, err
+	}
+
+	for _, key := range typedTemplateFields {
+		delete(fields, key)
+	}
+
+	interfaces, _ := fields["interfaces"].([]interface{})
+	delete(fields, "interfaces")
+
+	for _, item := range interfaces {
+		iface, _ := item.(map[string]interface{})
+		for key := range iface {
+			if !typedInterfaceFields[key] {
+				fields["interfaces"] = interfaces
+			}
+		}
+	}
+
+	return fields, nil
+}
+
+// deviceTemplateHCL renders a wug_device resource for a template, in the
+// layout of terraform fmt.
+func deviceTemplateHCL(name, options string, template *wugapi.DeviceTemplate, extra map[string]interface{}) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "resource \"wug_device\" %s {\n", hclString(name))
+
+	hclAttributes(&b, "  ", [][2]string{
+		{"name", hclString(template.Name)},
+		{"options", hclString(options)},
+		{"action_policy", hclString(template.ActionPolicy)},
+		{"device_type", hclString(template.DeviceType)},
+		{"snmp_oid", hclString(template.SnmpOid)},
+		{"primary_role", hclString(template.PrimaryRole)},
+		{"os", hclString(template.Os)},
+		{"brand", hclString(template.Brand)},
+	})
+
+	if len(template.SubRoles) > 0 {
+		b.WriteString("\n  subroles = [\n")
+		for _, subrole := range template.SubRoles {
+			fmt.Fprintf(&b, "    %s,\n", hclString(subrole))
+		}
+		b.WriteString("  ]\n")
+	}
+
+	for _, group := range template.Groups {
+		parents := make([]string, 0, len(group.Parents))
+		for _, parent := range group.Parents {
+			parents = append(parents, hclString(parent))
+		}
+
+		hclBlock(&b, "groups", [][2]string{
+			{"name", hclString(group.Name)},
+			{"parents", "[" + strings.Join(parents, ", ") + "]"},
+		})
+	}
+
+	for _, iface := range template.Interfaces {
+		hclBlock(&b, "interface", [][2]string{
+			{"default", strconv.FormatBool(iface.IsDefault)},
+			{"network_name", hclString(iface.NetworkName)},
+			{"network_address", hclString(iface.NetworkAddress)},
+			{"poll_using_network_name", strconv.FormatBool(iface.PollUsingNetworkName)},
+		})
+	}
+
+	for _, credential := range template.Credentials {
+		hclBlock(&b, "credential", [][2]string{
+			{"type", hclString(credential.CredentialType)},
+			{"name", hclString(credential.Name)},
+		})
+	}
+
+	for _, monitor := range template.ActiveMonitors {
+		critical, _ := strconv.ParseBool(monitor.IsCritical)
+
+		attributes := [][2]string{{"name", hclString(monitor.Name)}}
+		if monitor.Argument != "" {
+			attributes = append(attributes, [2]string{"argument", hclString(monitor.Argument)})
+		}
+		if monitor.Comment != "" {
+			attributes = append(attributes, [2]string{"comment", hclString(monitor.Comment)})
+		}
+		if critical {
+			attributes = append(attributes, [2]string{"critical", "true"})
+		}
+		if monitor.PollingOrder != 0 {
+			attributes = append(attributes, [2]string{"polling_order", strconv.Itoa(monitor.PollingOrder)})
+		}
+
+		hclBlock(&b, "active_monitor", attributes)
+	}
+
+	for _, monitor := range template.PerformanceMonitors {
+		hclBlock(&b, "performance_monitor", [][2]string{{"name", hclString(monitor.Name)}})
+	}
+
+	if len(extra) > 0 {
+		/* Keys are sorted by the encoder, so the snippet is stable. */
+		indented, _ := json.MarshalIndent(extra, "", "  ")
+		fmt.Fprintf(&b, "\n  template_json = <<EOT\n%s\nEOT\n", hclTemplateEscape(string(indented)))
+	}
+
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+// hclBlock renders a nested block of a resource.
+func hclBlock(b *strings.Builder, name string, attributes [][2]string) {
+	fmt.Fprintf(b, "\n  %s {\n", name)
+	hclAttributes(b, "    ", attributes)
+	b.WriteString("  }\n")
+}
+
+// hclAttributes renders attributes with their equal signs aligned, skipping
+// empty strings.
+func hclAttributes(b *strings.Builder, indent string, attributes [][2]string) {
+	width := 0
+	kept := make([][2]string, 0, len(attributes))
+	for _, attribute := range attributes {
+		if attribute[1] == `""` {
+			continue
+		}
+		if len(attribute[0]) > width {
+			width = len(attribute[0])
+		}
+		kept = append(kept, attribute)
+	}
+
+	for _, attribute := range kept {
+		fmt.Fprintf(b, "%s%-*s = %s\n", indent, width, attribute[0], attribute[1])
+	}
+}
+
+// hclString quotes a string for HCL, which knows fewer escapes than Go.
+func hclString(s string) string {
+	var b strings.Builder
+
+	b.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&b, `\u%04x`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+
+	return hclTemplateEscape(b.String())
+}
+
+// hclTemplateEscape escapes the interpolation and directive sequences of
+// HCL strings and heredocs.
+func hclTemplateEscape(s string) string {
+	return strings.NewReplacer("${", "$${", "%{", "%%{").Replace(s)
+}
@@ -0,0 +1,144 @@
+package wug
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+
+	"github.com/nerimcloud/terraform-provider-wug/wugapi/wugtest"
+)
+
+/* testAccGoldenDevice seeds a hand-tuned device, with notes and an interface
+ * timeout that wug_device has no argument for. */
+func testAccGoldenDevice(srv *wugtest.Server) string {
+	return srv.AddDevice(wugtest.Object{
+		"displayName":  "golden",
+		"primaryRole":  "Server",
+		"os":           "Debian",
+		"actionPolicy": "Mail Policy",
+		"subRoles":     []interface{}{"Linux"},
+		"notes":        "Tuned by hand, ${do not} interpolate",
+		"groups": []interface{}{
+			wugtest.Object{"name": "Linux", "parents": []interface{}{wugtest.RootGroupName, "Datacenter"}},
+		},
+		"interfaces": []interface{}{
+			wugtest.Object{"defaultInterface": true, "networkAddress": "10.0.0.1", "networkName": "golden.example.com", "timeout": 5},
+		},
+		"credentials":    []interface{}{wugtest.Object{"credentialType": "SNMP", "credential": "public-v2"}},
+		"activeMonitors": []interface{}{wugtest.Object{"name": "Ping", "isCritical": "true"}},
+	})
+}
+
+/* testAccGoldenDeviceHCL is the export of the golden device, under the given
+ * root group. */
+const testAccGoldenDeviceHCL = `resource "wug_device" "clone" {
+  name          = "golden"
+  options       = "basic"
+  action_policy = "Mail Policy"
+  primary_role  = "Server"
+  os            = "Debian"
+
+  subroles = [
+    "Linux",
+  ]
+
+  groups {
+    name    = "Linux"
+    parents = ["%s", "Datacenter"]
+  }
+
+  interface {
+    default                 = true
+    network_name            = "golden.example.com"
+    network_address         = "10.0.0.1"
+    poll_using_network_name = false
+  }
+
+  credential {
+    type = "SNMP"
+    name = "public-v2"
+  }
+
+  active_monitor {
+    name     = "Ping"
+    critical = true
+  }
+
+  template_json = <<EOT
+{
+  "interfaces": [
+    {
+      "defaultInterface": true,
+      "networkAddress": "10.0.0.1",
+      "networkName": "golden.example.com",
+      "timeout": 5
+    }
+  ],
+  "notes": "Tuned by hand, $${do not} interpolate"
+}
+EOT
+}
+`
+
+func TestAccDataSourceDeviceTemplate_basic(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	goldenID := testAccGoldenDevice(srv)
+
+	var hcl string
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceDeviceTemplateConfig(srv, goldenID),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.wug_device_template.test", "id", goldenID),
+					resource.TestMatchResourceAttr("data.wug_device_template.test", "template_json", regexp.MustCompile(`"notes":"Tuned by hand`)),
+					resource.TestMatchResourceAttr("data.wug_device_template.test", "template_json", regexp.MustCompile(`"timeout":5`)),
+					resource.TestCheckResourceAttr("data.wug_device_template.test", "hcl", fmt.Sprintf(testAccGoldenDeviceHCL, wugtest.RootGroupName)),
+					func(s *terraform.State) error {
+						hcl = s.RootModule().Resources["data.wug_device_template.test"].Primary.Attributes["hcl"]
+						return nil
+					},
+				),
+			},
+			{
+				Config:      testAccDataSourceDeviceTemplateConfig(srv, "999"),
+				ExpectError: regexp.MustCompile("Unable to read device 999"),
+			},
+		},
+	})
+
+	/* The snippet applies as is, and clones the extra fields. */
+	srv.DeleteDevice(goldenID)
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDeviceDestroy(srv),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(srv) + hcl,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDeviceExists(srv, "wug_device.clone"),
+					testAccCheckDeviceTemplate(srv, "wug_device.clone", "notes", "Tuned by hand, ${do not} interpolate"),
+					testAccCheckDeviceCredentials(srv, "wug_device.clone", "SNMP/public-v2"),
+					resource.TestMatchResourceAttr("wug_device.clone", "rendered_template_json", regexp.MustCompile(`"timeout":5`)),
+				),
+			},
+		},
+	})
+}
+
+func testAccDataSourceDeviceTemplateConfig(srv *wugtest.Server, deviceID string) string {
+	return testAccProviderConfig(srv) + fmt.Sprintf(`
+data "wug_device_template" "test" {
+  device_id     = %q
+  resource_name = "clone"
+}
+`, deviceID)
+}
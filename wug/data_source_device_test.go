@@ -0,0 +1,110 @@
+package wug
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+
+	"github.com/nerimcloud/terraform-provider-wug/wugapi/wugtest"
+)
+
+/* testAccDevices seeds web-01 and web-011, so that searches for web-01 find
+ * both, and returns the ID of web-01. */
+func testAccDevices(srv *wugtest.Server) (webID string) {
+	webID = srv.AddDevice(wugtest.Object{
+		"displayName":  "web-01",
+		"primaryRole":  "Server",
+		"os":           "Debian",
+		"brand":        "VMware, Inc.",
+		"actionPolicy": "Mail Policy",
+		"subRoles":     []interface{}{"Linux"},
+		"groups": []interface{}{
+			wugtest.Object{"name": "Linux", "parents": []interface{}{wugtest.RootGroupName, "Datacenter"}},
+		},
+		"interfaces": []interface{}{
+			wugtest.Object{"defaultInterface": true, "networkAddress": "10.0.0.1", "networkName": "web-01.example.com"},
+			wugtest.Object{"networkAddress": "10.0.1.1", "networkName": "web-01-backup.example.com"},
+		},
+		"credentials":    []interface{}{wugtest.Object{"credentialType": "SNMP", "credential": "public-v2"}},
+		"activeMonitors": []interface{}{wugtest.Object{"name": "Ping", "isCritical": "true"}},
+	})
+
+	srv.AddDevice(wugtest.Object{
+		"displayName": "web-011",
+		"interfaces": []interface{}{
+			wugtest.Object{"defaultInterface": true, "networkAddress": "10.0.0.11", "networkName": "web-011.example.com"},
+		},
+	})
+
+	return webID
+}
+
+func TestAccDataSourceDevice_basic(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	webID := testAccDevices(srv)
+
+	for _, lookup := range []string{
+		`name = "web-01"`,
+		`network_address = "10.0.0.1"`,
+		`hostname = "WEB-01.example.com"`,
+	} {
+		resource.Test(t, resource.TestCase{
+			ProviderFactories: testAccProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: testAccDataSourceDeviceConfig(srv, lookup),
+					Check: resource.ComposeTestCheckFunc(
+						resource.TestCheckResourceAttr("data.wug_device.test", "id", webID),
+						resource.TestCheckResourceAttr("data.wug_device.test", "name", "web-01"),
+						resource.TestCheckResourceAttr("data.wug_device.test", "network_address", "10.0.0.1"),
+						resource.TestCheckResourceAttr("data.wug_device.test", "hostname", "web-01.example.com"),
+						resource.TestCheckResourceAttr("data.wug_device.test", "os", "Debian"),
+						resource.TestCheckResourceAttr("data.wug_device.test", "primary_role", "Server"),
+						resource.TestCheckResourceAttr("data.wug_device.test", "action_policy", "Mail Policy"),
+						resource.TestCheckResourceAttr("data.wug_device.test", "subroles.0", "Linux"),
+						resource.TestCheckResourceAttr("data.wug_device.test", "groups.0.name", "Linux"),
+						resource.TestCheckResourceAttr("data.wug_device.test", "groups.0.parents.1", "Datacenter"),
+						resource.TestCheckResourceAttr("data.wug_device.test", "interface.#", "2"),
+						resource.TestCheckResourceAttr("data.wug_device.test", "credential.0.name", "public-v2"),
+						resource.TestCheckResourceAttr("data.wug_device.test", "active_monitor.0.name", "Ping"),
+						resource.TestCheckResourceAttr("data.wug_device.test", "active_monitor.0.critical", "true"),
+					),
+				},
+			},
+		})
+	}
+}
+
+func TestAccDataSourceDevice_notFound(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	testAccDevices(srv)
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				/* The search matches web-01, but not its default interface. */
+				Config:      testAccDataSourceDeviceConfig(srv, `network_address = "10.0.1.1"`),
+				ExpectError: regexp.MustCompile("Found no device for 10.0.1.1"),
+			},
+			{
+				Config:      testAccDataSourceDeviceConfig(srv, `name = "web"`),
+				ExpectError: regexp.MustCompile("Found no device for web"),
+			},
+		},
+	})
+}
+
+func testAccDataSourceDeviceConfig(srv *wugtest.Server, lookup string) string {
+	return testAccProviderConfig(srv) + fmt.Sprintf(`
+data "wug_device" "test" {
+  %s
+}
+`, lookup)
+}
@@ -0,0 +1,171 @@
+package wug
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/nerimcloud/terraform-provider-wug/wugapi"
+)
+
+func dataSourceDevices() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceDevicesRead,
+
+		Timeouts: &schema.ResourceTimeout{
+			Read: schema.DefaultTimeout(5 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"search": {
+				Type:        schema.TypeString,
+				Description: "Text to look for in the display name, network address or host name of the devices.",
+				Optional:    true,
+			},
+			"group_id": {
+				Type:        schema.TypeString,
+				Description: "ID of the group to list the devices of, every device when unset.",
+				Optional:    true,
+			},
+			"recursive": {
+				Type:         schema.TypeBool,
+				Description:  "Whether to list the devices of the subgroups of group_id too.",
+				Optional:     true,
+				Default:      false,
+				RequiredWith: []string{"group_id"},
+			},
+			"role": {
+				Type:        schema.TypeString,
+				Description: "Primary role of the devices, ignoring case.",
+				Optional:    true,
+			},
+			"device_type": {
+				Type:        schema.TypeString,
+				Description: "Type of the devices, ignoring case.",
+				Optional:    true,
+			},
+			"ids": {
+				Type:        schema.TypeList,
+				Description: "IDs of the matching devices.",
+				Computed:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"devices": {
+				Type:        schema.TypeList,
+				Description: "Summaries of the matching devices.",
+				Computed:    true,
+				Elem: &schema.Resource{Schema: map[string]*schema.Schema{
+					"id": {
+						Type:        schema.TypeString,
+						Description: "ID of the device.",
+						Computed:    true,
+					},
+					"name": {
+						Type:        schema.TypeString,
+						Description: "Display name of the device.",
+						Computed:    true,
+					},
+					"network_address": {
+						Type:        schema.TypeString,
+						Description: "Network address of the default interface.",
+						Computed:    true,
+					},
+					"hostname": {
+						Type:        schema.TypeString,
+						Description: "Host name of the default interface.",
+						Computed:    true,
+					},
+					"role": {
+						Type:        schema.TypeString,
+						Description: "Primary role of the device.",
+						Computed:    true,
+					},
+					"device_type": {
+						Type:        schema.TypeString,
+						Description: "Type of the device.",
+						Computed:    true,
+					},
+					"brand": {
+						Type:        schema.TypeString,
+						Description: "Brand of the device.",
+						Computed:    true,
+					},
+					"os": {
+						Type:        schema.TypeString,
+						Description: "OS of the device.",
+						Computed:    true,
+					},
+				}},
+			},
+		},
+	}
+}
+
+func dataSourceDevicesRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*wugapi.Client)
+
+	search := d.Get("search").(string)
+	groupID := d.Get("group_id").(string)
+	recursive := d.Get("recursive").(bool)
+
+	var devices []wugapi.DeviceSummary
+	var err error
+
+	if groupID != "" {
+		devices, err = client.SearchDeviceGroupDevices(ctx, groupID, search, recursive)
+	} else {
+		devices, err = client.SearchDevices(ctx, search)
+	}
+	if err != nil {
+		/* Only a group lookup can point at group_id. */
+		var path cty.Path
+		if groupID != "" {
+			path = faultPath(err, cty.GetAttrPath("group_id"))
+		}
+		return errorDiag(ctx, "Unable to list devices", err, path)
+	}
+
+	/* WUG cannot filter listings on these, do it here. */
+	role := d.Get("role").(string)
+	deviceType := d.Get("device_type").(string)
+
+	ids := make([]string, 0, len(devices))
+	summaries := make([]map[string]interface{}, 0, len(devices))
+
+	for _, device := range devices {
+		if role != "" && !strings.EqualFold(device.Role, role) {
+			continue
+		}
+		if deviceType != "" && !strings.EqualFold(device.DeviceType, deviceType) {
+			continue
+		}
+
+		ids = append(ids, device.ID)
+		summaries = append(summaries, map[string]interface{}{
+			"id":              device.ID,
+			"name":            device.Name,
+			"network_address": device.NetworkAddress,
+			"hostname":        device.HostName,
+			"role":            device.Role,
+			"device_type":     device.DeviceType,
+			"brand":           device.Brand,
+			"os":              device.OS,
+		})
+	}
+
+	d.Set("ids", ids)
+	d.Set("devices", summaries)
+
+	/* The listing has no ID of its own, derive a stable one from the
+	 * query. */
+	d.SetId(strings.Join([]string{search, groupID, strconv.FormatBool(recursive), role, deviceType}, "/"))
+
+	return nil
+}
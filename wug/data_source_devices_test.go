@@ -0,0 +1,118 @@
+package wug
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+
+	"github.com/nerimcloud/terraform-provider-wug/wugapi/wugtest"
+)
+
+/* testAccDeviceFleet seeds web-01 to web-05 in My Network/Datacenter/Linux,
+ * with even devices being databases, and db-01 in My Network/Datacenter.
+ * It returns the IDs of the Datacenter and Linux groups. */
+func testAccDeviceFleet(srv *wugtest.Server) (datacenterID, linuxID string) {
+	datacenterID = srv.AddDeviceGroup(wugtest.RootGroupID, "Datacenter")
+	linuxID = srv.AddDeviceGroup(datacenterID, "Linux")
+
+	for i := 1; i <= 5; i++ {
+		role := "Web Server"
+		if i%2 == 0 {
+			role = "Database"
+		}
+
+		srv.AddDevice(wugtest.Object{
+			"displayName": fmt.Sprintf("web-%02d", i),
+			"primaryRole": role,
+			"deviceType":  "Linux Server",
+			"groups": []interface{}{
+				wugtest.Object{"name": "Linux", "parents": []interface{}{wugtest.RootGroupName, "Datacenter"}},
+			},
+			"interfaces": []interface{}{
+				wugtest.Object{"defaultInterface": true, "networkAddress": fmt.Sprintf("10.0.0.%d", i), "networkName": fmt.Sprintf("web-%02d.example.com", i)},
+			},
+		})
+	}
+
+	srv.AddDevice(wugtest.Object{
+		"displayName": "db-01",
+		"primaryRole": "Database",
+		"deviceType":  "Windows Server",
+		"groups": []interface{}{
+			wugtest.Object{"name": "Datacenter", "parents": []interface{}{wugtest.RootGroupName}},
+		},
+	})
+
+	return datacenterID, linuxID
+}
+
+func TestAccDataSourceDevices_basic(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	/* Every listing spans several pages. */
+	srv.PageSize = 2
+
+	datacenterID, linuxID := testAccDeviceFleet(srv)
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceDevicesConfig(srv, ""),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.wug_devices.test", "ids.#", "6"),
+					resource.TestCheckResourceAttr("data.wug_devices.test", "devices.#", "6"),
+					resource.TestCheckResourceAttr("data.wug_devices.test", "devices.0.name", "web-01"),
+					resource.TestCheckResourceAttr("data.wug_devices.test", "devices.0.network_address", "10.0.0.1"),
+					resource.TestCheckResourceAttr("data.wug_devices.test", "devices.0.hostname", "web-01.example.com"),
+					resource.TestCheckResourceAttr("data.wug_devices.test", "devices.0.role", "Web Server"),
+					resource.TestCheckResourceAttr("data.wug_devices.test", "devices.0.device_type", "Linux Server"),
+				),
+			},
+			{
+				Config: testAccDataSourceDevicesConfig(srv, `search = "example.com"`),
+				Check:  resource.TestCheckResourceAttr("data.wug_devices.test", "ids.#", "5"),
+			},
+			{
+				Config: testAccDataSourceDevicesConfig(srv, fmt.Sprintf("group_id = %q", datacenterID)),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.wug_devices.test", "ids.#", "1"),
+					resource.TestCheckResourceAttr("data.wug_devices.test", "devices.0.name", "db-01"),
+				),
+			},
+			{
+				Config: testAccDataSourceDevicesConfig(srv, fmt.Sprintf("group_id = %q\n  recursive = true", datacenterID)),
+				Check:  resource.TestCheckResourceAttr("data.wug_devices.test", "ids.#", "6"),
+			},
+			{
+				Config: testAccDataSourceDevicesConfig(srv, fmt.Sprintf("group_id = %q\n  role = \"database\"", linuxID)),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.wug_devices.test", "ids.#", "2"),
+					resource.TestCheckResourceAttr("data.wug_devices.test", "devices.0.name", "web-02"),
+					resource.TestCheckResourceAttr("data.wug_devices.test", "devices.1.name", "web-04"),
+				),
+			},
+			{
+				Config: testAccDataSourceDevicesConfig(srv, `device_type = "Windows Server"`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.wug_devices.test", "ids.#", "1"),
+					resource.TestCheckResourceAttr("data.wug_devices.test", "devices.0.name", "db-01"),
+				),
+			},
+			{
+				Config: testAccDataSourceDevicesConfig(srv, `search = "nothing"`),
+				Check:  resource.TestCheckResourceAttr("data.wug_devices.test", "ids.#", "0"),
+			},
+		},
+	})
+}
+
+func testAccDataSourceDevicesConfig(srv *wugtest.Server, query string) string {
+	return testAccProviderConfig(srv) + fmt.Sprintf(`
+data "wug_devices" "test" {
+  %s
+}
+`, query)
+}
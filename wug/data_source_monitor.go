@@ -1,42 +1,25 @@
 package wug
 
 import (
-	"encoding/json"
-	"errors"
-	"fmt"
+	"context"
+	"time"
 
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
-	"github.com/tidwall/gjson"
-)
-
-
-// MonitorSearch is WUG's internal object.
-type MonitorInfo struct {
-	Type                string                             `json:"type,omitempty"`
-	Search	            string                             `json:"search,omitempty"`
-	ClassId	            string                             `json:"classId,omitempty"`
-	MonitorName         string                             `json:"monitorName,omitempty"`
-}
-
-// MonitorTypeInfo is WUG's internal object.
-type MonitorTypeInfo struct {
-	ClassId                 string                             `json:"classId,omitempty"`
-	BaseType	        string                             `json:"baseType,omitempty"`
-}
 
-// MonitorTemplate is WUG's internal object.
-type MonitorSearchTemplate struct {
-	MonitorId               string                             `json:"monitorId,omitempty"`
-	Name			string                             `json:"name,omitempty"`
-	Description             string                             `json:"description,omitempty"`
-	Id			string                             `json:"id,omitempty"`
-	MonitorTypeInfo		MonitorTypeInfo                    `json:"monitorTypeInfo,omitempty"`
-}
+	"github.com/nerimcloud/terraform-provider-wug/wugapi"
+)
 
 func dataSourceMonitor() *schema.Resource {
 	return &schema.Resource{
-		Read:   dataSourceMonitorRead,
+		ReadContext: dataSourceMonitorRead,
+
+		Timeouts: &schema.ResourceTimeout{
+			Read: schema.DefaultTimeout(5 * time.Minute),
+		},
+
 		Schema: map[string]*schema.Schema{
 			"type": &schema.Schema{
 				Type:        schema.TypeString,
@@ -46,7 +29,7 @@ func dataSourceMonitor() *schema.Resource {
 				ValidateFunc: validation.StringInSlice([]string{
 					"active",
 					"performance",
-//					"passive",
+					//					"passive",
 				}, true),
 			},
 			"search": &schema.Schema{
@@ -71,52 +54,25 @@ func dataSourceMonitor() *schema.Resource {
 	}
 }
 
-func dataSourceMonitorRead(d *schema.ResourceData, m interface{}) error {
-	resty := m.(*Client).Resty
-	token := m.(*Client).Token
-	config := m.(*Client).Config
-
-	params := map[string]string{
-		"type": d.Get("type").(string),
-		"search": d.Get("search").(string),
-		"includeDeviceMonitors": "true",
-		"includeSystemMonitors": "true",
-		"includeCoreMonitors": "true",
-	}
-
-	resp, err := resty.R().
-		SetQueryParams(params).
-		SetHeader("Accept", "application/json").
-		SetAuthToken(token).
-		Get(config.URL + "/monitors/-")
+func dataSourceMonitorRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*wugapi.Client)
 
+	monitors, err := client.SearchMonitors(ctx, d.Get("type").(string), d.Get("search").(string))
 	if err != nil {
-		return err
-	} else if resp.StatusCode() != 200 {
-		return errors.New(string(resp.Body()))
-	} else if resp.StatusCode() == 404 {
-		/* The monitor does not exist anymore. */
-		d.SetId("")
-		return errors.New(string(resp.Body()))
+		return errorDiag(ctx, "Unable to search monitors", err, nil)
 	}
 
-	monitorCount := gjson.GetBytes(resp.Body(), "paging.size").Int()
-	if monitorCount == 0 {
-		return fmt.Errorf("Found no monitor for " + d.Get("search").(string))
+	if len(monitors) == 0 {
+		return diag.Diagnostics{
+			diag.Diagnostic{
+				Severity:      diag.Error,
+				Summary:       "Found no monitor for " + d.Get("search").(string),
+				AttributePath: cty.GetAttrPath("search"),
+			},
+		}
 	}
 
-	var data MonitorSearchTemplate
-	var mode string
-	if d.Get("type").(string) == "active" {
-		mode = "data.activeMonitors.0"
-	}
-	if d.Get("type").(string) == "performance" {
-		mode = "data.performanceMonitors.0"
-	}
-	err = json.Unmarshal([]byte(gjson.GetBytes(resp.Body(), mode).Raw), &data)
-	if err != nil {
-		return err
-	}
+	data := monitors[0]
 
 	d.Set("class_id", data.MonitorTypeInfo.ClassId)
 	d.Set("monitor_name", data.Name)
@@ -124,4 +80,3 @@ func dataSourceMonitorRead(d *schema.ResourceData, m interface{}) error {
 
 	return nil
 }
-
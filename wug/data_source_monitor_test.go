@@ -0,0 +1,54 @@
+package wug
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+
+	"github.com/nerimcloud/terraform-provider-wug/wugapi/wugtest"
+)
+
+func TestAccDataSourceMonitor_basic(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceMonitorConfig(srv, "performance", "CPU"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.wug_monitor.test", "id", "3"),
+					resource.TestCheckResourceAttr("data.wug_monitor.test", "class_id", "cpu-class"),
+					resource.TestCheckResourceAttr("data.wug_monitor.test", "monitor_name", "CPU Utilization"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccDataSourceMonitor_notFound(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccDataSourceMonitorConfig(srv, "active", "Does Not Exist"),
+				ExpectError: regexp.MustCompile("Found no monitor for Does Not Exist"),
+			},
+		},
+	})
+}
+
+func testAccDataSourceMonitorConfig(srv *wugtest.Server, monitorType, search string) string {
+	return testAccProviderConfig(srv) + fmt.Sprintf(`
+data "wug_monitor" "test" {
+  type   = %q
+  search = %q
+}
+`, monitorType, search)
+}
@@ -0,0 +1,73 @@
+package wug
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/nerimcloud/terraform-provider-wug/wugapi"
+)
+
+// errorDiag wraps an error into a diagnostic. When path is not nil, Terraform
+// points at that attribute in the configuration.
+func errorDiag(ctx context.Context, summary string, err error, path cty.Path) diag.Diagnostics {
+	detail := err.Error()
+
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		detail = "The operation timed out, consider raising the resource timeouts: " + detail
+	case errors.Is(ctx.Err(), context.Canceled):
+		detail = "The operation was cancelled: " + detail
+	}
+
+	return diag.Diagnostics{
+		diag.Diagnostic{
+			Severity:      diag.Error,
+			Summary:       summary,
+			Detail:        detail,
+			AttributePath: path,
+		},
+	}
+}
+
+// faultPath returns path when WUG rejected the request because of the
+// argument it points at, with a 400 about its value or a 404 on the ID it
+// holds. Transport and server errors are nobody's fault in the
+// configuration, and get no path.
+func faultPath(err error, path cty.Path) cty.Path {
+	if errors.Is(err, wugapi.ErrNotFound) {
+		return path
+	}
+
+	return rejectedPath(err, path)
+}
+
+// rejectedPath returns path when WUG rejected the value of the argument it
+// points at with a 400, e.g. a filter it cannot parse.
+func rejectedPath(err error, path cty.Path) cty.Path {
+	var apiErr *wugapi.Error
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusBadRequest {
+		return path
+	}
+
+	return nil
+}
+
+// goneDiag drops a resource deleted outside of Terraform from the state, so
+// that the next plan recreates it.
+func goneDiag(d *schema.ResourceData, what string) diag.Diagnostics {
+	id := d.Id()
+	d.SetId("")
+
+	return diag.Diagnostics{
+		diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  what + " " + id + " not found, removing it from state",
+			Detail:   "It was probably deleted outside of Terraform, and will be recreated on the next apply.",
+		},
+	}
+}
@@ -0,0 +1,47 @@
+package wug
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+
+	"github.com/nerimcloud/terraform-provider-wug/wugapi"
+)
+
+func TestRejectedPath(t *testing.T) {
+	path := cty.GetAttrPath("filter")
+
+	if got := rejectedPath(&wugapi.Error{StatusCode: http.StatusBadRequest}, path); !got.Equals(path) {
+		t.Errorf("expected %#v for a 400, got %#v", path, got)
+	}
+	if got := rejectedPath(&wugapi.Error{StatusCode: http.StatusNotFound}, path); got != nil {
+		t.Errorf("expected no path for a 404, got %#v", got)
+	}
+}
+
+func TestFaultPath(t *testing.T) {
+	path := cty.GetAttrPath("group_id")
+
+	for name, tc := range map[string]struct {
+		err  error
+		want cty.Path
+	}{
+		"bad request":  {&wugapi.Error{StatusCode: http.StatusBadRequest}, path},
+		"not found":    {fmt.Errorf("listing: %w", &wugapi.Error{StatusCode: http.StatusNotFound}), path},
+		"no such ID":   {fmt.Errorf("device 42: %w", wugapi.ErrNotFound), path},
+		"unauthorized": {&wugapi.Error{StatusCode: http.StatusUnauthorized}, nil},
+		"server error": {&wugapi.Error{StatusCode: http.StatusInternalServerError}, nil},
+		"transport":    {context.DeadlineExceeded, nil},
+		"other":        {errors.New("no device ID returned"), nil},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if got := faultPath(tc.err, path); !got.Equals(tc.want) {
+				t.Errorf("expected %#v, got %#v", tc.want, got)
+			}
+		})
+	}
+}
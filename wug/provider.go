@@ -1,7 +1,13 @@
 package wug
 
 import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	"github.com/nerimcloud/terraform-provider-wug/wugapi"
 )
 
 // Provider exports WUG terraform provider schemas.
@@ -34,22 +40,87 @@ func Provider() *schema.Provider {
 				DefaultFunc: schema.EnvDefaultFunc("WUG_ALLOW_UNVERIFIED_SSL", false),
 				Description: "If set, WUG client will permit unverifiable SSL certificates.",
 			},
+			"ca_cert_file": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				DefaultFunc:   schema.EnvDefaultFunc("WUG_CA_CERT_FILE", nil),
+				ConflictsWith: []string{"ca_cert_pem"},
+				Description:   "Path to a PEM bundle of CA certificates used to verify the WUG server.",
+			},
+			"ca_cert_pem": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				DefaultFunc:   schema.EnvDefaultFunc("WUG_CA_CERT_PEM", nil),
+				ConflictsWith: []string{"ca_cert_file"},
+				Description:   "PEM bundle of CA certificates used to verify the WUG server.",
+			},
+			"client_cert": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("WUG_CLIENT_CERT", nil),
+				RequiredWith: []string{"client_key"},
+				Description:  "Client certificate for mutual TLS, as a file path or PEM content.",
+			},
+			"client_key": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Sensitive:    true,
+				DefaultFunc:  schema.EnvDefaultFunc("WUG_CLIENT_KEY", nil),
+				RequiredWith: []string{"client_cert"},
+				Description:  "Client private key for mutual TLS, as a file path or PEM content.",
+			},
+			"tls_server_name": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("WUG_TLS_SERVER_NAME", nil),
+				Description: "Server name used to verify the WUG certificate, when it differs from the URL host.",
+			},
+			"max_retries": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("WUG_MAX_RETRIES", wugapi.DefaultMaxRetries),
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Maximum number of retries on transient API failures (429, 502, 503, 504, connection resets).",
+			},
+			"retry_max_wait": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("WUG_RETRY_MAX_WAIT", int(wugapi.DefaultRetryMaxWait.Seconds())),
+				ValidateFunc: validation.IntAtLeast(1),
+				Description:  "Maximum time in seconds to wait between two retries.",
+			},
 		},
 		DataSourcesMap: map[string]*schema.Resource{
-			"wug_monitor": dataSourceMonitor(),
+			"wug_device":          dataSourceDevice(),
+			"wug_device_group":    dataSourceDeviceGroup(),
+			"wug_device_template": dataSourceDeviceTemplate(),
+			"wug_devices":         dataSourceDevices(),
+			"wug_monitor":         dataSourceMonitor(),
 		},
 		ResourcesMap: map[string]*schema.Resource{
-			"wug_device": resourceDevice(),
-			"wug_monitor": resourceMonitor(),
+			"wug_device":                  resourceDevice(),
+			"wug_device_batch":            resourceDeviceBatch(),
+			"wug_device_attribute":        resourceDeviceAttribute(),
+			"wug_device_group":            resourceDeviceGroup(),
+			"wug_device_group_membership": resourceDeviceGroupMembership(),
+			"wug_dynamic_group":           resourceDynamicGroup(),
+			"wug_maintenance":             resourceMaintenance(),
+			"wug_monitor":                 resourceMonitor(),
 		},
-		ConfigureFunc: providerConfigure,
+		ConfigureContextFunc: providerConfigure,
 	}
 }
 
-func providerConfigure(d *schema.ResourceData) (interface{}, error) {
+func providerConfigure(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
 	c, err := NewConfig(d)
 	if err != nil {
-		return nil, err
+		return nil, diag.FromErr(err)
 	}
-	return c.Client()
+
+	client, err := wugapi.NewClient(ctx, c)
+	if err != nil {
+		return nil, errorDiag(ctx, "Unable to authenticate against WUG", err, nil)
+	}
+
+	return client, nil
 }
@@ -0,0 +1,36 @@
+package wug
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/nerimcloud/terraform-provider-wug/wugapi/wugtest"
+)
+
+/* Acceptance tests run against the in-process wugtest server: TF_ACC=1 is
+ * enough, no WUG instance is needed. */
+var testAccProviderFactories = map[string]func() (*schema.Provider, error){
+	"wug": func() (*schema.Provider, error) {
+		return Provider(), nil
+	},
+}
+
+func TestProvider(t *testing.T) {
+	if err := Provider().InternalValidate(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+}
+
+// testAccProviderConfig points the provider at a fake WUG server.
+func testAccProviderConfig(srv *wugtest.Server) string {
+	return fmt.Sprintf(`
+provider "wug" {
+  url         = %q
+  user        = %q
+  password    = %q
+  max_retries = 0
+}
+`, srv.URL(), wugtest.User, wugtest.Password)
+}
@@ -1,98 +1,72 @@
 package wug
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/structure"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
-	"github.com/tidwall/gjson"
+
+	"github.com/nerimcloud/terraform-provider-wug/logging"
+	"github.com/nerimcloud/terraform-provider-wug/wugapi"
 )
 
-// DeviceTemplateInterface is WUG's internal object.
-type DeviceTemplateInterface struct {
-	IsDefault            bool   `json:"defaultInterface,omitempty"`
-	PollUsingNetworkName bool   `json:"pollUsingNetworkName,omitempty"`
-	NetworkAddress       string `json:"networkAddress,omitempty"`
-	NetworkName          string `json:"networkName,omitempty"`
-}
+var deviceLogger = logging.New("wug_device")
 
-// DeviceTemplateReferenceName is WUG's internal object.
-type DeviceTemplateReferenceName struct {
-	Name    string   `json:"name,omitempty"`
-	Parents []string `json:"parents,omitempty"`
-}
-
-// DeviceTemplateCredentials is WUG's internal object.
-type DeviceTemplateCredentials struct {
-	CredentialType string `json:"credentialType,omitempty"`
-	Name           string `json:"credential,omitempty"`
-}
-
-// DeviceTemplateActiveMonitor is WUG's internal object.
-type DeviceTemplateActiveMonitor struct {
-	Name         string `json:"name,omitempty"`
-	Argument     string `json:"argument,omitempty"`
-	Comment      string `json:"comment,omitempty"`
-	IsCritical   string `json:"isCritical,omitempty"`
-	PollingOrder int    `json:"pollingOrder,string,omitempty"`
-}
+func resourceDevice() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceDeviceCreate,
+		ReadContext:   resourceDeviceRead,
+		UpdateContext: resourceDeviceUpdate,
+		DeleteContext: resourceDeviceDelete,
 
-// DeviceTemplatePerformanceMonitor is WUG's internal object.
-type DeviceTemplatePerformanceMonitor struct {
-	Name string `json:"name,omitempty"`
-}
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
 
-// DeviceTemplate is WUG's internal object.
-type DeviceTemplate struct {
-	Name                string                             `json:"displayName,omitempty"`
-	Interfaces          []DeviceTemplateInterface          `json:"interfaces,omitempty"`
-	Groups              []DeviceTemplateReferenceName      `json:"groups,omitempty"`
-	Credentials         []DeviceTemplateCredentials        `json:"credentials,omitempty"`
-	ActiveMonitors      []DeviceTemplateActiveMonitor      `json:"activeMonitors,omitempty"`
-	PerformanceMonitors []DeviceTemplatePerformanceMonitor `json:"performanceMonitors,omitempty"`
-	DeviceType          string                             `json:"deviceType,omitempty"`
-	SnmpOid             string                             `json:"snmpOid,omitempty"`
-	PrimaryRole         string                             `json:"primaryRole,omitempty"`
-	SubRoles            []string                           `json:"subRoles,omitempty"`
-	Os                  string                             `json:"os,omitempty"`
-	Brand               string                             `json:"brand,omitempty"`
-	ActionPolicy        string                             `json:"actionPolicy,omitempty"`
-}
+		CustomizeDiff: resourceDeviceCustomizeDiff,
 
-func resourceDevice() *schema.Resource {
-	return &schema.Resource{
-		Create: resourceDeviceCreate,
-		Read:   resourceDeviceRead,
-		/* No Update path since all fields are ForceNew. */
-		/* Update: resourceDeviceUpdate, */
-		Delete: resourceDeviceDelete,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
 
 		Schema: map[string]*schema.Schema{
 			"name": &schema.Schema{
 				Type:        schema.TypeString,
 				Description: "Display name of the device.",
 				Required:    true,
-				ForceNew:    true,
 			},
 			"options": &schema.Schema{
 				Type:        schema.TypeString,
-				Description: "Set of options for applying the template (either l2 or basic).",
+				Description: "Set of options for applying the template (either l2 or basic). Only used on creation.",
 				Required:    true,
-				ForceNew:    true,
 				ValidateFunc: validation.StringInSlice([]string{
 					"l2",
 					"basic",
 				}, true),
+				/* Only used when applying the template, so WUG cannot
+				 * tell it back for imported devices. */
+				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+					return old == "" && d.Id() != ""
+				},
 			},
 			"groups": &schema.Schema{
 				Type:        schema.TypeList,
-				Description: "List of groups that device will be added to.",
-				Required:    true,
-				ForceNew:    true,
+				Description: "List of groups that device will be added to. Leave it unset when memberships are managed with wug_device_group_membership.",
+				Optional:    true,
+				Computed:    true,
 				Elem: &schema.Resource{Schema: map[string]*schema.Schema{
 					"parents": &schema.Schema{
 						Type:        schema.TypeList,
@@ -112,8 +86,7 @@ func resourceDevice() *schema.Resource {
 			"interface": &schema.Schema{
 				Type:        schema.TypeSet,
 				Required:    true,
-				Description: "Interfaces.",
-				ForceNew:    true,
+				Description: "Interfaces, matched by network address on update.",
 				Elem: &schema.Resource{Schema: map[string]*schema.Schema{
 					"default": &schema.Schema{
 						Type:        schema.TypeBool,
@@ -142,8 +115,7 @@ func resourceDevice() *schema.Resource {
 			"credential": &schema.Schema{
 				Type:        schema.TypeSet,
 				Optional:    true,
-				ForceNew:    true,
-				Description: "Credentials.",
+				Description: "Credentials of the library, at most one per type.",
 				Elem: &schema.Resource{Schema: map[string]*schema.Schema{
 					"type": &schema.Schema{
 						Type:        schema.TypeString,
@@ -160,7 +132,6 @@ func resourceDevice() *schema.Resource {
 			"active_monitor": &schema.Schema{
 				Type:        schema.TypeSet,
 				Optional:    true,
-				ForceNew:    true,
 				Description: "Active monitors.",
 				Elem: &schema.Resource{Schema: map[string]*schema.Schema{
 					"name": &schema.Schema{
@@ -187,7 +158,7 @@ func resourceDevice() *schema.Resource {
 					"polling_order": &schema.Schema{
 						Type:        schema.TypeInt,
 						Optional:    true,
-						Description: "Monitor polling order.",
+						Description: "Monitor polling order, WUG picks one for a critical monitor when 0.",
 						Default:     0,
 					},
 				}},
@@ -195,7 +166,6 @@ func resourceDevice() *schema.Resource {
 			"performance_monitor": &schema.Schema{
 				Type:        schema.TypeSet,
 				Optional:    true,
-				ForceNew:    true,
 				Description: "Performance monitors.",
 				Elem: &schema.Resource{Schema: map[string]*schema.Schema{
 					"name": &schema.Schema{
@@ -209,25 +179,21 @@ func resourceDevice() *schema.Resource {
 				Type:        schema.TypeString,
 				Description: "Type of the device.",
 				Optional:    true,
-				ForceNew:    true,
 			},
 			"snmp_oid": &schema.Schema{
 				Type:        schema.TypeString,
 				Description: "SNMP OID of the device.",
 				Optional:    true,
-				ForceNew:    true,
 			},
 			"primary_role": &schema.Schema{
 				Type:        schema.TypeString,
 				Description: "Primary role of the device.",
 				Optional:    true,
-				ForceNew:    true,
 			},
 			"subroles": &schema.Schema{
 				Type:        schema.TypeList,
 				Description: "Subroles of the device.",
 				Optional:    true,
-				ForceNew:    true,
 				Elem: &schema.Schema{
 					Type: schema.TypeString,
 				},
@@ -236,45 +202,73 @@ func resourceDevice() *schema.Resource {
 				Type:        schema.TypeString,
 				Description: "OS of the device.",
 				Optional:    true,
-				ForceNew:    true,
 			},
 			"brand": &schema.Schema{
 				Type:        schema.TypeString,
 				Description: "Brand of the device.",
 				Optional:    true,
-				ForceNew:    true,
 			},
 			"action_policy": &schema.Schema{
 				Type:        schema.TypeString,
 				Description: "Policy how to get notified.",
 				Optional:    true,
-				ForceNew:    true,
+			},
+			"template_json": &schema.Schema{
+				Type:         schema.TypeString,
+				Description:  "Device template exported from WUG, as JSON. The other arguments take precedence over its fields. Only used on creation, changing it later does nothing.",
+				Optional:     true,
+				ValidateFunc: validateTemplateJSON,
+				/* WUG cannot tell it back, so it is only known as sent
+				 * on creation. */
+				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+					return d.Id() != "" || structure.SuppressJsonDiff(k, old, new, d)
+				},
+			},
+			"rendered_template_json": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "Device template sent to WUG on creation, as JSON.",
+				Computed:    true,
 			},
 		},
 	}
 }
 
-func resourceDeviceCreate(d *schema.ResourceData, m interface{}) error {
-	wugResty := m.(*Client).Resty
-	token := m.(*Client).Token
-	config := m.(*Client).Config
+// validateTemplateJSON checks that a template is a JSON object.
+func validateTemplateJSON(i interface{}, k string) ([]string, []error) {
+	var template map[string]interface{}
+	if err := json.Unmarshal([]byte(i.(string)), &template); err != nil {
+		return nil, []error{fmt.Errorf("%q must be a JSON object: %s", k, err)}
+	}
+
+	return nil, nil
+}
 
-	var template DeviceTemplate
+// expandDeviceTemplate builds a device template from the attributes shared
+// by wug_device and the devices of wug_device_batch, read with get.
+func expandDeviceTemplate(get func(string) interface{}) wugapi.DeviceTemplate {
+	var template wugapi.DeviceTemplate
 
 	/* Build our template object. */
 
-	template.Name = d.Get("name").(string)
-	template.DeviceType = d.Get("device_type").(string)
-	template.SnmpOid = d.Get("snmp_oid").(string)
-	template.PrimaryRole = d.Get("primary_role").(string)
-	template.Os = d.Get("os").(string)
-	template.Brand = d.Get("brand").(string)
-	template.ActionPolicy = d.Get("action_policy").(string)
+	template.Name = get("name").(string)
+	template.DeviceType = get("device_type").(string)
+	template.SnmpOid = get("snmp_oid").(string)
+	template.PrimaryRole = get("primary_role").(string)
+	template.Os = get("os").(string)
+	template.Brand = get("brand").(string)
+	template.ActionPolicy = get("action_policy").(string)
 
-	groupList := d.Get("groups").([]interface{})
-	template.Groups = make([]DeviceTemplateReferenceName, 0)
+	/* Validated by validateTemplateJSON. Numbers are kept as written. */
+	if raw := get("template_json").(string); raw != "" {
+		decoder := json.NewDecoder(strings.NewReader(raw))
+		decoder.UseNumber()
+		decoder.Decode(&template.Extra)
+	}
+
+	groupList := get("groups").([]interface{})
+	template.Groups = make([]wugapi.DeviceTemplateReferenceName, 0)
 	for _, group := range groupList {
-		var refName DeviceTemplateReferenceName
+		var refName wugapi.DeviceTemplateReferenceName
 		refName.Name = group.(map[string]interface{})["name"].(string)
 
 		parents := group.(map[string]interface{})["parents"].([]interface{})
@@ -286,16 +280,16 @@ func resourceDeviceCreate(d *schema.ResourceData, m interface{}) error {
 		template.Groups = append(template.Groups, refName)
 	}
 
-	subRoles := d.Get("subroles").([]interface{})
+	subRoles := get("subroles").([]interface{})
 	template.SubRoles = make([]string, 0)
 	for _, subrole := range subRoles {
 		template.SubRoles = append(template.SubRoles, subrole.(string))
 	}
 
-	interfaceList := d.Get("interface").(*schema.Set).List()
-	template.Interfaces = make([]DeviceTemplateInterface, 0)
+	interfaceList := get("interface").(*schema.Set).List()
+	template.Interfaces = make([]wugapi.DeviceTemplateInterface, 0)
 	for _, iface := range interfaceList {
-		template.Interfaces = append(template.Interfaces, DeviceTemplateInterface{
+		template.Interfaces = append(template.Interfaces, wugapi.DeviceTemplateInterface{
 			IsDefault:            iface.(map[string]interface{})["default"].(bool),
 			PollUsingNetworkName: iface.(map[string]interface{})["poll_using_network_name"].(bool),
 			NetworkAddress:       iface.(map[string]interface{})["network_address"].(string),
@@ -303,19 +297,19 @@ func resourceDeviceCreate(d *schema.ResourceData, m interface{}) error {
 		})
 	}
 
-	credentialList := d.Get("credential").(*schema.Set).List()
-	template.Credentials = make([]DeviceTemplateCredentials, 0)
+	credentialList := get("credential").(*schema.Set).List()
+	template.Credentials = make([]wugapi.DeviceTemplateCredentials, 0)
 	for _, cred := range credentialList {
-		template.Credentials = append(template.Credentials, DeviceTemplateCredentials{
+		template.Credentials = append(template.Credentials, wugapi.DeviceTemplateCredentials{
 			CredentialType: cred.(map[string]interface{})["type"].(string),
 			Name:           cred.(map[string]interface{})["name"].(string),
 		})
 	}
 
-	activeMonitorsList := d.Get("active_monitor").(*schema.Set).List()
-	template.ActiveMonitors = make([]DeviceTemplateActiveMonitor, 0)
+	activeMonitorsList := get("active_monitor").(*schema.Set).List()
+	template.ActiveMonitors = make([]wugapi.DeviceTemplateActiveMonitor, 0)
 	for _, mon := range activeMonitorsList {
-		template.ActiveMonitors = append(template.ActiveMonitors, DeviceTemplateActiveMonitor{
+		template.ActiveMonitors = append(template.ActiveMonitors, wugapi.DeviceTemplateActiveMonitor{
 			Name:         mon.(map[string]interface{})["name"].(string),
 			Argument:     mon.(map[string]interface{})["argument"].(string),
 			Comment:      mon.(map[string]interface{})["comment"].(string),
@@ -324,86 +318,92 @@ func resourceDeviceCreate(d *schema.ResourceData, m interface{}) error {
 		})
 	}
 
-	performanceMonitorsList := d.Get("performance_monitor").(*schema.Set).List()
-	template.PerformanceMonitors = make([]DeviceTemplatePerformanceMonitor, 0)
+	performanceMonitorsList := get("performance_monitor").(*schema.Set).List()
+	template.PerformanceMonitors = make([]wugapi.DeviceTemplatePerformanceMonitor, 0)
 	for _, mon := range performanceMonitorsList {
-		template.PerformanceMonitors = append(template.PerformanceMonitors, DeviceTemplatePerformanceMonitor{
+		template.PerformanceMonitors = append(template.PerformanceMonitors, wugapi.DeviceTemplatePerformanceMonitor{
 			Name: mon.(map[string]interface{})["name"].(string),
 		})
 	}
 
-	params := map[string]interface{}{
-		"options": []string{
-			d.Get("options").(string),
-		},
-		"templates": []DeviceTemplate{
-			template,
-		},
-	}
+	return template
+}
 
-	resp, err := wugResty.R().
-		SetHeader("Content-Type", "application/json").
-		SetAuthToken(token).
-		SetBody(params).
-		Patch(config.URL + "/devices/-/config/template")
+func resourceDeviceCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*wugapi.Client)
 
-	if err != nil {
-		return err
-	} else if resp.StatusCode() != 200 {
-		return errors.New(string(resp.Body()))
-	}
+	template := expandDeviceTemplate(d.Get)
 
-	deviceID := gjson.GetBytes(resp.Body(), "data.idMap.0.resultId").String()
+	idMap, err := client.ApplyDeviceTemplates(ctx,
+		[]string{d.Get("options").(string)},
+		[]wugapi.DeviceTemplate{template},
+	)
 
-	if len(deviceID) == 0 {
-		return errors.New(string(resp.Body()))
+	if err != nil {
+		/* The arguments are checked on plan, template_json is not: a
+		 * template WUG rejects is its fault. */
+		var path cty.Path
+		var failures wugapi.DeviceTemplateErrors
+		if errors.As(err, &failures) && d.Get("template_json").(string) != "" {
+			path = cty.GetAttrPath("template_json")
+		}
+
+		return errorDiag(ctx, "Unable to apply the device template", err, path)
 	}
 
-	d.SetId(deviceID)
+	/* WUG may accept the template without creating any device. */
+	if idMap[0].ResultID == "" {
+		return errorDiag(ctx, "Unable to apply the device template",
+			fmt.Errorf("no device ID returned for template %s", template.Name), nil)
+	}
 
-	log.Printf("[WUG] Created device with ID: %s\n", d.Id())
+	d.SetId(idMap[0].ResultID)
 
-	return resourceDeviceRead(d, m)
-}
+	rendered, _ := json.Marshal(template)
+	d.Set("rendered_template_json", string(rendered))
 
-func resourceDeviceRead(d *schema.ResourceData, m interface{}) error {
-	resty := m.(*Client).Resty
-	token := m.(*Client).Token
-	config := m.(*Client).Config
+	deviceLogger.Infof("Created device with ID: %s", d.Id())
 
-	id := d.Id()
+	return resourceDeviceRead(ctx, d, m)
+}
 
-	resp, err := resty.R().
-		SetHeader("Content-Type", "application/json").
-		SetAuthToken(token).
-		Get(config.URL + "/devices/" + id + "/config/template")
+func resourceDeviceRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*wugapi.Client)
 
-	if err != nil {
-		return err
-	} else if resp.StatusCode() != 200 {
-		return errors.New(string(resp.Body()))
-	} else if resp.StatusCode() == 404 {
-		/* The device does not exist anymore. */
-		d.SetId("")
-		return errors.New(string(resp.Body()))
+	template, err := client.GetDeviceTemplate(ctx, d.Id())
+	if errors.Is(err, wugapi.ErrNotFound) {
+		return goneDiag(d, "Device")
+	} else if err != nil {
+		return errorDiag(ctx, "Unable to read device "+d.Id(), err, nil)
 	}
 
-	deviceCount := gjson.GetBytes(resp.Body(), "data.deviceCount").Int()
-
-	if deviceCount != 1 {
-		return fmt.Errorf("Found invalid device count for %s: %d", id, deviceCount)
+	/* An imported device has no state yet, and manages every monitor. */
+	if d.Get("name").(string) != "" {
+		template.ActiveMonitors = managedActiveMonitors(d.Get("active_monitor"), template.ActiveMonitors)
+		template.PerformanceMonitors = managedPerformanceMonitors(d.Get("performance_monitor"), template.PerformanceMonitors)
 	}
 
-	var template DeviceTemplate
-	err = json.Unmarshal([]byte(gjson.GetBytes(resp.Body(), "data.templates.0").Raw), &template)
-	if err != nil {
-		return err
-	}
+	flattenDeviceTemplate(d, template)
+
+	return nil
+}
 
+// flattenDeviceTemplate sets the attributes shared by the wug_device resource
+// and data source from a device template.
+func flattenDeviceTemplate(d *schema.ResourceData, template *wugapi.DeviceTemplate) {
 	d.Set("name", template.Name)
-	d.Set("groups", template.Groups)
 
 	/* Reformat arrays since the field names may change... */
+	groups := make([]map[string]interface{}, 0)
+	for _, group := range template.Groups {
+		groups = append(groups, map[string]interface{}{
+			"name":    group.Name,
+			"parents": group.Parents,
+		})
+	}
+
+	d.Set("groups", groups)
+
 	interfaces := make([]map[string]interface{}, 0)
 	for _, iface := range template.Interfaces {
 		interfaces = append(interfaces, map[string]interface{}{
@@ -428,18 +428,26 @@ func resourceDeviceRead(d *schema.ResourceData, m interface{}) error {
 
 	activeMonitors := make([]map[string]interface{}, 0)
 	for _, mon := range template.ActiveMonitors {
+		critical, _ := strconv.ParseBool(mon.IsCritical)
 		activeMonitors = append(activeMonitors, map[string]interface{}{
 			"name":          mon.Name,
 			"argument":      mon.Argument,
 			"comment":       mon.Comment,
-			"critical":      mon.IsCritical,
+			"critical":      critical,
 			"polling_order": mon.PollingOrder,
 		})
 	}
 
 	d.Set("active_monitor", activeMonitors)
 
-	d.Set("performance_monitor", template.PerformanceMonitors)
+	performanceMonitors := make([]map[string]interface{}, 0)
+	for _, mon := range template.PerformanceMonitors {
+		performanceMonitors = append(performanceMonitors, map[string]interface{}{
+			"name": mon.Name,
+		})
+	}
+
+	d.Set("performance_monitor", performanceMonitors)
 
 	d.Set("device_type", template.DeviceType)
 	d.Set("snmp_oid", template.SnmpOid)
@@ -448,30 +456,586 @@ func resourceDeviceRead(d *schema.ResourceData, m interface{}) error {
 	d.Set("os", template.Os)
 	d.Set("brand", template.Brand)
 	d.Set("action_policy", template.ActionPolicy)
+}
+
+func resourceDeviceUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*wugapi.Client)
+
+	/* A change of options alone has nothing to apply. */
+	if diags := updateDevice(ctx, client, d.Id(), d, nil); diags != nil {
+		return diags
+	}
+
+	return resourceDeviceRead(ctx, d, m)
+}
+
+// deviceChange is the change of the attributes of a device, as planned for
+// wug_device or for a device block of wug_device_batch.
+type deviceChange interface {
+	HasChange(key string) bool
+	GetChange(key string) (interface{}, interface{})
+}
+
+// devicePropertyKeys are the attributes updated with the device properties.
+var devicePropertyKeys = []string{"name", "device_type", "snmp_oid", "primary_role", "subroles", "os", "brand", "action_policy"}
+
+// hasChanges reports whether any of the keys changed.
+func hasChanges(change deviceChange, keys ...string) bool {
+	for _, key := range keys {
+		if change.HasChange(key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// updateDevice applies the changes of the properties, groups, interfaces,
+// credentials and monitors of a device in place. Diagnostics point at the
+// changed attribute, under path for the device block of a batch.
+func updateDevice(ctx context.Context, client *wugapi.Client, id string, change deviceChange, path cty.Path) diag.Diagnostics {
+	pathOf := func(key string) cty.Path {
+		return path.GetAttr(key)
+	}
+
+	if hasChanges(change, devicePropertyKeys...) {
+		/* Blame the first property changed, WUG does not tell which one it
+		 * rejected. */
+		var changed string
+		for _, key := range devicePropertyKeys {
+			if change.HasChange(key) {
+				changed = key
+				break
+			}
+		}
+
+		get := func(key string) interface{} {
+			_, n := change.GetChange(key)
+			return n
+		}
+
+		properties := wugapi.DeviceProperties{
+			Name:         get("name").(string),
+			DeviceType:   get("device_type").(string),
+			SnmpOid:      get("snmp_oid").(string),
+			PrimaryRole:  get("primary_role").(string),
+			SubRoles:     make([]string, 0),
+			Os:           get("os").(string),
+			Brand:        get("brand").(string),
+			ActionPolicy: get("action_policy").(string),
+		}
+
+		for _, subrole := range get("subroles").([]interface{}) {
+			properties.SubRoles = append(properties.SubRoles, subrole.(string))
+		}
+
+		if err := client.UpdateDeviceProperties(ctx, id, properties); err != nil {
+			return errorDiag(ctx, "Unable to update device "+id, err, faultPath(err, pathOf(changed)))
+		}
+
+		deviceLogger.Infof("Updated properties of device %s", id)
+	}
+
+	if change.HasChange("groups") {
+		o, n := change.GetChange("groups")
+		if diags := updateDeviceGroups(ctx, client, id, o, n, pathOf("groups")); diags != nil {
+			return diags
+		}
+	}
+
+	if change.HasChange("interface") {
+		o, n := change.GetChange("interface")
+		if diags := updateDeviceInterfaces(ctx, client, id, o, n, pathOf("interface")); diags != nil {
+			return diags
+		}
+	}
+
+	if change.HasChange("credential") {
+		o, n := change.GetChange("credential")
+		if diags := updateDeviceCredentials(ctx, client, id, o, n, pathOf("credential")); diags != nil {
+			return diags
+		}
+	}
+
+	for _, monitorType := range []string{"active", "performance"} {
+		if key := monitorType + "_monitor"; change.HasChange(key) {
+			o, n := change.GetChange(key)
+			if diags := updateDeviceMonitors(ctx, client, id, monitorType, o, n, pathOf(key)); diags != nil {
+				return diags
+			}
+		}
+	}
 
 	return nil
 }
 
-func resourceDeviceUpdate(d *schema.ResourceData, m interface{}) error {
-	return resourceDeviceRead(d, m)
+// groupPaths returns the full path of each entry of a groups list, keyed by
+// its "/"-joined form.
+func groupPaths(groups interface{}) map[string][]string {
+	paths := make(map[string][]string)
+
+	for _, group := range groups.([]interface{}) {
+		path := make([]string, 0)
+		for _, parent := range group.(map[string]interface{})["parents"].([]interface{}) {
+			path = append(path, parent.(string))
+		}
+		path = append(path, group.(map[string]interface{})["name"].(string))
+
+		paths[strings.Join(path, "/")] = path
+	}
+
+	return paths
+}
+
+// updateDeviceGroups moves the device between existing groups, without
+// applying its template again.
+func updateDeviceGroups(ctx context.Context, client *wugapi.Client, id string, o, n interface{}, path cty.Path) diag.Diagnostics {
+	oldPaths, newPaths := groupPaths(o), groupPaths(n)
+
+	tree, err := client.GetDeviceGroupTree(ctx)
+	if err != nil {
+		return errorDiag(ctx, "Unable to list device groups", err, nil)
+	}
+
+	for key, groupPath := range newPaths {
+		if _, ok := oldPaths[key]; ok {
+			continue
+		}
+
+		group, err := tree.Find(groupPath)
+		if err != nil {
+			return errorDiag(ctx, "Unable to add device "+id+" to group "+key, err, path)
+		}
+		if err := client.UpdateDeviceGroupMembers(ctx, group.ID, []string{id}, nil); err != nil {
+			return errorDiag(ctx, "Unable to add device "+id+" to group "+key, err, faultPath(err, path))
+		}
+
+		deviceLogger.Infof("Added device %s to group %s", id, group.ID)
+	}
+
+	for key, groupPath := range oldPaths {
+		if _, ok := newPaths[key]; ok {
+			continue
+		}
+
+		/* A group deleted in the meantime has no member to remove. */
+		group, err := tree.Find(groupPath)
+		if errors.Is(err, wugapi.ErrNotFound) {
+			continue
+		} else if err != nil {
+			return errorDiag(ctx, "Unable to remove device "+id+" from group "+key, err, path)
+		}
+		if err := client.UpdateDeviceGroupMembers(ctx, group.ID, nil, []string{id}); err != nil {
+			return errorDiag(ctx, "Unable to remove device "+id+" from group "+key, err, faultPath(err, path))
+		}
+
+		deviceLogger.Infof("Removed device %s from group %s", id, group.ID)
+	}
+
+	return nil
 }
 
-func resourceDeviceDelete(d *schema.ResourceData, m interface{}) error {
-	resty := m.(*Client).Resty
-	token := m.(*Client).Token
-	config := m.(*Client).Config
+func resourceDeviceCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	defaults := 0
+	for _, iface := range d.Get("interface").(*schema.Set).List() {
+		if iface.(map[string]interface{})["default"].(bool) {
+			defaults++
+		}
+	}
+
+	if defaults > 1 {
+		return errors.New("only one interface can be the default one")
+	}
 
-	id := d.Id()
+	/* Check the credentials against the library, rather than failing the
+	 * template or the update half way. */
+	if !d.HasChange("credential") || !d.NewValueKnown("credential") {
+		return nil
+	}
 
-	resp, err := resty.R().
-		SetHeader("Content-Type", "application/json").
-		SetAuthToken(token).
-		Delete(config.URL + "/devices/" + id)
+	credentials := credentialsByType(d.Get("credential"))
+	if len(credentials) != d.Get("credential").(*schema.Set).Len() {
+		return errors.New("only one credential per type can be assigned to a device")
+	}
 
+	library, err := m.(*wugapi.Client).ListCredentials(ctx)
 	if err != nil {
-		return err
-	} else if resp.StatusCode() != 200 {
-		return errors.New(string(resp.Body()))
+		return fmt.Errorf("unable to list the credential library: %w", err)
+	}
+
+	for _, credential := range credentials {
+		if _, err := wugapi.FindCredential(library, credential.CredentialType, credential.Name); err != nil {
+			return fmt.Errorf("credential %q of type %s not found in the credential library", credential.Name, credential.CredentialType)
+		}
+	}
+
+	return nil
+}
+
+// credentialsByType indexes a credential set by lower-cased type, as WUG
+// matches types case-insensitively.
+func credentialsByType(credentials interface{}) map[string]wugapi.DeviceTemplateCredentials {
+	indexed := make(map[string]wugapi.DeviceTemplateCredentials)
+
+	for _, item := range credentials.(*schema.Set).List() {
+		credential := item.(map[string]interface{})
+		indexed[strings.ToLower(credential["type"].(string))] = wugapi.DeviceTemplateCredentials{
+			CredentialType: credential["type"].(string),
+			Name:           credential["name"].(string),
+		}
+	}
+
+	return indexed
+}
+
+// updateDeviceCredentials assigns the new credentials and removes the ones
+// whose type is gone, keeping the device and its history. Assigning a
+// credential replaces the one of the same type.
+func updateDeviceCredentials(ctx context.Context, client *wugapi.Client, id string, o, n interface{}, path cty.Path) diag.Diagnostics {
+	oldCredentials, newCredentials := credentialsByType(o), credentialsByType(n)
+
+	library, err := client.ListCredentials(ctx)
+	if err != nil {
+		return errorDiag(ctx, "Unable to list the credential library", err, nil)
+	}
+
+	for credentialType, reference := range newCredentials {
+		if old, ok := oldCredentials[credentialType]; ok && old.Name == reference.Name {
+			continue
+		}
+
+		credential, err := wugapi.FindCredential(library, reference.CredentialType, reference.Name)
+		if err != nil {
+			return errorDiag(ctx, "Unable to assign credential "+reference.Name+" to device "+id, err, path)
+		}
+
+		if err := client.AssignDeviceCredential(ctx, id, credential.ID); err != nil {
+			return errorDiag(ctx, "Unable to assign credential "+reference.Name+" to device "+id, err, faultPath(err, path))
+		}
+		deviceLogger.Infof("Assigned credential %s to device %s", reference.Name, id)
+	}
+
+	assigned, err := client.ListDeviceCredentials(ctx, id)
+	if err != nil {
+		return errorDiag(ctx, "Unable to list the credentials of device "+id, err, nil)
+	}
+
+	for _, credential := range assigned {
+		if _, keep := newCredentials[strings.ToLower(credential.Type)]; keep {
+			continue
+		}
+
+		if err := client.RemoveDeviceCredential(ctx, id, credential.ID); err != nil {
+			return errorDiag(ctx, "Unable to remove credential "+credential.Name+" from device "+id, err, faultPath(err, path))
+		}
+		deviceLogger.Infof("Removed credential %s from device %s", credential.Name, id)
+	}
+
+	return nil
+}
+
+// interfacesByAddress indexes an interface set by network address.
+func interfacesByAddress(interfaces interface{}) map[string]wugapi.DeviceInterface {
+	indexed := make(map[string]wugapi.DeviceInterface)
+
+	for _, item := range interfaces.(*schema.Set).List() {
+		iface := item.(map[string]interface{})
+		indexed[iface["network_address"].(string)] = wugapi.DeviceInterface{
+			IsDefault:            iface["default"].(bool),
+			PollUsingNetworkName: iface["poll_using_network_name"].(bool),
+			NetworkAddress:       iface["network_address"].(string),
+			NetworkName:          iface["network_name"].(string),
+		}
+	}
+
+	return indexed
+}
+
+// updateDeviceInterfaces adds, changes and removes interfaces in place, so
+// that the device keeps its ID and monitors. Interfaces are added and
+// changed first, so that the default interface is moved before the former
+// one is removed.
+func updateDeviceInterfaces(ctx context.Context, client *wugapi.Client, deviceID string, o, n interface{}, path cty.Path) diag.Diagnostics {
+	oldInterfaces, newInterfaces := interfacesByAddress(o), interfacesByAddress(n)
+
+	current, err := client.ListDeviceInterfaces(ctx, deviceID)
+	if err != nil {
+		return errorDiag(ctx, "Unable to list the interfaces of device "+deviceID, err, nil)
+	}
+
+	ids := make(map[string]string)
+	for _, iface := range current {
+		ids[iface.NetworkAddress] = iface.ID
+	}
+
+	/* The default interface goes last, as setting it clears the flag on
+	 * every other interface. */
+	addresses := make([]string, 0, len(newInterfaces))
+	for address := range newInterfaces {
+		addresses = append(addresses, address)
+	}
+	sort.Slice(addresses, func(i, j int) bool {
+		a, b := newInterfaces[addresses[i]], newInterfaces[addresses[j]]
+		if a.IsDefault != b.IsDefault {
+			return b.IsDefault
+		}
+		return addresses[i] < addresses[j]
+	})
+
+	for _, address := range addresses {
+		iface := newInterfaces[address]
+
+		if id, ok := ids[address]; !ok {
+			if _, err := client.AddDeviceInterface(ctx, deviceID, iface); err != nil {
+				return errorDiag(ctx, "Unable to add interface "+address+" to device "+deviceID, err, faultPath(err, path))
+			}
+			deviceLogger.Infof("Added interface %s to device %s", address, deviceID)
+		} else if iface != oldInterfaces[address] {
+			if err := client.UpdateDeviceInterface(ctx, deviceID, id, iface); err != nil {
+				return errorDiag(ctx, "Unable to update interface "+address+" of device "+deviceID, err, faultPath(err, path))
+			}
+			deviceLogger.Infof("Updated interface %s of device %s", address, deviceID)
+		}
+	}
+
+	for address := range oldInterfaces {
+		id, ok := ids[address]
+		if _, keep := newInterfaces[address]; keep || !ok {
+			continue
+		}
+
+		if err := client.RemoveDeviceInterface(ctx, deviceID, id); err != nil {
+			return errorDiag(ctx, "Unable to remove interface "+address+" from device "+deviceID, err, faultPath(err, path))
+		}
+		deviceLogger.Infof("Removed interface %s from device %s", address, deviceID)
+	}
+
+	return nil
+}
+
+// expandActiveMonitor turns an active_monitor entry into its template form.
+func expandActiveMonitor(monitor map[string]interface{}) wugapi.DeviceTemplateActiveMonitor {
+	return wugapi.DeviceTemplateActiveMonitor{
+		Name:         monitor["name"].(string),
+		Argument:     monitor["argument"].(string),
+		Comment:      monitor["comment"].(string),
+		IsCritical:   strconv.FormatBool(monitor["critical"].(bool)),
+		PollingOrder: monitor["polling_order"].(int),
+	}
+}
+
+// sameActiveMonitor reports whether a monitor found in WUG is the one
+// wanted, a critical monitor wanted without polling order taking the one WUG
+// picked.
+func sameActiveMonitor(want, got wugapi.DeviceTemplateActiveMonitor) bool {
+	wantCritical, _ := strconv.ParseBool(want.IsCritical)
+	gotCritical, _ := strconv.ParseBool(got.IsCritical)
+
+	if want.Name != got.Name || want.Argument != got.Argument || want.Comment != got.Comment || wantCritical != gotCritical {
+		return false
+	}
+
+	return !wantCritical || want.PollingOrder == 0 || want.PollingOrder == got.PollingOrder
+}
+
+// managedActiveMonitors keeps the active monitors of a template found in the
+// state of the device, since WUG also lists those assigned by wug_monitor.
+// A monitor changed outside of Terraform is kept as found, to show as a diff.
+func managedActiveMonitors(state interface{}, monitors []wugapi.DeviceTemplateActiveMonitor) []wugapi.DeviceTemplateActiveMonitor {
+	managed := make([]wugapi.DeviceTemplateActiveMonitor, 0)
+	taken := make([]bool, len(monitors))
+	changed := make([]wugapi.DeviceTemplateActiveMonitor, 0)
+
+	for _, item := range state.(*schema.Set).List() {
+		want := expandActiveMonitor(item.(map[string]interface{}))
+
+		found := false
+		for i, monitor := range monitors {
+			if !taken[i] && sameActiveMonitor(want, monitor) {
+				taken[i], found = true, true
+				managed = append(managed, want)
+				break
+			}
+		}
+
+		if !found {
+			changed = append(changed, want)
+		}
+	}
+
+	for _, want := range changed {
+		for i, monitor := range monitors {
+			if !taken[i] && monitor.Name == want.Name && monitor.Argument == want.Argument {
+				taken[i] = true
+				managed = append(managed, monitor)
+				break
+			}
+		}
+	}
+
+	return managed
+}
+
+// managedPerformanceMonitors keeps the performance monitors of a template
+// found in the state of the device.
+func managedPerformanceMonitors(state interface{}, monitors []wugapi.DeviceTemplatePerformanceMonitor) []wugapi.DeviceTemplatePerformanceMonitor {
+	wanted := make(map[string]int)
+	for _, item := range state.(*schema.Set).List() {
+		wanted[item.(map[string]interface{})["name"].(string)]++
+	}
+
+	managed := make([]wugapi.DeviceTemplatePerformanceMonitor, 0)
+	for _, monitor := range monitors {
+		if wanted[monitor.Name] > 0 {
+			wanted[monitor.Name]--
+			managed = append(managed, monitor)
+		}
+	}
+
+	return managed
+}
+
+// monitorsByKey indexes a monitor set by name and argument, as monitor
+// assignments of the given type. The same monitor may be assigned several
+// times with different arguments.
+func monitorsByKey(monitorType string, monitors interface{}) map[string]wugapi.MonitorTemplate {
+	indexed := make(map[string]wugapi.MonitorTemplate)
+
+	for _, item := range monitors.(*schema.Set).List() {
+		monitor := item.(map[string]interface{})
+
+		assignment := wugapi.MonitorTemplate{
+			Type:            monitorType,
+			MonitorTypeName: monitor["name"].(string),
+		}
+
+		if monitorType == "active" {
+			assignment.Active.Argument = monitor["argument"].(string)
+			assignment.Active.Comment = monitor["comment"].(string)
+
+			/* WUG numbers the critical monitors from 1. */
+			if monitor["critical"].(bool) {
+				assignment.Active.CriticalOrder = monitor["polling_order"].(int)
+				if assignment.Active.CriticalOrder < 1 {
+					assignment.Active.CriticalOrder = 1
+				}
+			}
+		}
+
+		indexed[assignment.MonitorTypeName+"/"+assignment.Active.Argument] = assignment
+	}
+
+	return indexed
+}
+
+// updateDeviceMonitors applies the change of a monitor set to the monitor
+// assignments of a device. A monitor is added or removed when its name or
+// argument changes, and its assignment updated otherwise.
+func updateDeviceMonitors(ctx context.Context, client *wugapi.Client, id, monitorType string, o, n interface{}, path cty.Path) diag.Diagnostics {
+	oldMonitors, newMonitors := monitorsByKey(monitorType, o), monitorsByKey(monitorType, n)
+
+	assigned, err := client.ListDeviceMonitors(ctx, id)
+	if err != nil {
+		return errorDiag(ctx, "Unable to list the monitors of device "+id, err, nil)
+	}
+
+	/* wug_monitor may assign the same monitor again, prefer the assignment
+	 * with the same comment. */
+	taken := make(map[string]bool)
+	assignment := func(monitor wugapi.MonitorTemplate) (wugapi.DeviceMonitor, bool) {
+		found := -1
+		for i, candidate := range assigned {
+			if taken[candidate.ID] || !strings.EqualFold(candidate.Type, monitorType) ||
+				candidate.MonitorTypeName != monitor.MonitorTypeName || candidate.Active.Argument != monitor.Active.Argument {
+				continue
+			}
+			if found < 0 || candidate.Active.Comment == monitor.Active.Comment {
+				found = i
+			}
+		}
+
+		if found < 0 {
+			return wugapi.DeviceMonitor{}, false
+		}
+
+		taken[assigned[found].ID] = true
+		return assigned[found], true
+	}
+
+	for key, monitor := range oldMonitors {
+		if _, ok := newMonitors[key]; ok {
+			continue
+		}
+
+		/* A monitor removed in the meantime has nothing to remove. */
+		current, ok := assignment(monitor)
+		if !ok {
+			continue
+		}
+		if err := client.RemoveDeviceMonitor(ctx, id, current.ID); err != nil {
+			return errorDiag(ctx, "Unable to remove monitor "+monitor.MonitorTypeName+" from device "+id, err, nil)
+		}
+		deviceLogger.Infof("Removed monitor %s from device %s", monitor.MonitorTypeName, id)
+	}
+
+	added := make([]wugapi.MonitorTemplate, 0)
+
+	for key, monitor := range newMonitors {
+		old, ok := oldMonitors[key]
+		if ok && old == monitor {
+			continue
+		}
+
+		current, ok := assignment(old)
+		if !ok {
+			added = append(added, monitor)
+			continue
+		}
+
+		/* Keep the parameters Terraform does not manage. */
+		update := current.MonitorTemplate
+		update.Active.Comment = monitor.Active.Comment
+		update.Active.CriticalOrder = monitor.Active.CriticalOrder
+
+		if err := client.UpdateDeviceMonitor(ctx, id, current.ID, update); err != nil {
+			return errorDiag(ctx, "Unable to update monitor "+monitor.MonitorTypeName+" of device "+id, err, faultPath(err, path))
+		}
+		deviceLogger.Infof("Updated monitor %s of device %s", monitor.MonitorTypeName, id)
+	}
+
+	for _, monitor := range added {
+		library, err := client.SearchMonitors(ctx, monitorType, monitor.MonitorTypeName)
+		if err != nil {
+			return errorDiag(ctx, "Unable to search monitors", err, nil)
+		}
+
+		found := false
+		for _, entry := range library {
+			if entry.Name == monitor.MonitorTypeName {
+				monitor.MonitorTypeClassId = entry.MonitorTypeInfo.ClassId
+				monitor.MonitorTypeId = entry.MonitorId
+				found = true
+				break
+			}
+		}
+		if !found {
+			return errorDiag(ctx, "Unable to add monitor "+monitor.MonitorTypeName+" to device "+id, fmt.Errorf("monitor %s: %w", monitor.MonitorTypeName, wugapi.ErrNotFound), path)
+		}
+
+		if _, err := client.AddDeviceMonitor(ctx, id, monitor); err != nil {
+			return errorDiag(ctx, "Unable to add monitor "+monitor.MonitorTypeName+" to device "+id, err, faultPath(err, path))
+		}
+		deviceLogger.Infof("Added monitor %s to device %s", monitor.MonitorTypeName, id)
+	}
+
+	return nil
+}
+
+func resourceDeviceDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*wugapi.Client)
+
+	if err := client.DeleteDevice(ctx, d.Id()); err != nil {
+		return errorDiag(ctx, "Unable to delete device "+d.Id(), err, nil)
 	}
 
 	d.SetId("")
@@ -0,0 +1,161 @@
+package wug
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/nerimcloud/terraform-provider-wug/logging"
+	"github.com/nerimcloud/terraform-provider-wug/wugapi"
+)
+
+var attributeLogger = logging.New("wug_device_attribute")
+
+func resourceDeviceAttribute() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceDeviceAttributeCreate,
+		ReadContext:   resourceDeviceAttributeRead,
+		UpdateContext: resourceDeviceAttributeUpdate,
+		DeleteContext: resourceDeviceAttributeDelete,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceDeviceAttributeImport,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"device_id": {
+				Type:        schema.TypeString,
+				Description: "ID of the device.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Description: "Name of the attribute, unique on the device.",
+				Required:    true,
+			},
+			"value": {
+				Type:        schema.TypeString,
+				Description: "Value of the attribute.",
+				Required:    true,
+			},
+		},
+	}
+}
+
+func resourceDeviceAttributeCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*wugapi.Client)
+
+	deviceID := d.Get("device_id").(string)
+
+	attributeID, err := client.AddDeviceAttribute(ctx, deviceID, wugapi.DeviceAttribute{
+		Name:  d.Get("name").(string),
+		Value: d.Get("value").(string),
+	})
+	if err != nil {
+		/* WUG answers 404 for an unknown device, 400 for a name it refuses. */
+		path := rejectedPath(err, cty.GetAttrPath("name"))
+		if errors.Is(err, wugapi.ErrNotFound) {
+			path = cty.GetAttrPath("device_id")
+		}
+		return errorDiag(ctx, "Unable to add the attribute to device "+deviceID, err, path)
+	}
+
+	d.SetId(deviceID + "/" + attributeID)
+
+	attributeLogger.Infof("Created attribute with ID: %s", d.Id())
+
+	return resourceDeviceAttributeRead(ctx, d, m)
+}
+
+// deviceAttributeID returns the attribute part of a "<deviceId>/<attributeId>"
+// resource ID.
+func deviceAttributeID(d *schema.ResourceData) string {
+	return strings.TrimPrefix(d.Id(), d.Get("device_id").(string)+"/")
+}
+
+func resourceDeviceAttributeRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*wugapi.Client)
+
+	attribute, err := client.GetDeviceAttribute(ctx, d.Get("device_id").(string), deviceAttributeID(d))
+	if errors.Is(err, wugapi.ErrNotFound) {
+		return goneDiag(d, "Device attribute")
+	} else if err != nil {
+		return errorDiag(ctx, "Unable to read device attribute "+d.Id(), err, nil)
+	}
+
+	d.Set("name", attribute.Name)
+	d.Set("value", attribute.Value)
+
+	return nil
+}
+
+// resourceDeviceAttributeImport splits a "<deviceId>/<attributeId>" or
+// "<deviceId>/<name>" import ID.
+func resourceDeviceAttributeImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	client := m.(*wugapi.Client)
+
+	parts := strings.SplitN(d.Id(), "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("unexpected import ID %q, expected <deviceId>/<attributeId> or <deviceId>/<name>", d.Id())
+	}
+
+	attributes, err := client.ListDeviceAttributes(ctx, parts[0])
+	if err != nil {
+		return nil, err
+	}
+
+	for _, attribute := range attributes {
+		if attribute.ID == parts[1] || attribute.Name == parts[1] {
+			d.Set("device_id", parts[0])
+			d.SetId(parts[0] + "/" + attribute.ID)
+
+			return []*schema.ResourceData{d}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("device %s has no attribute %q", parts[0], parts[1])
+}
+
+func resourceDeviceAttributeUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*wugapi.Client)
+
+	if d.HasChanges("name", "value") {
+		err := client.UpdateDeviceAttribute(ctx, d.Get("device_id").(string), deviceAttributeID(d), wugapi.DeviceAttribute{
+			Name:  d.Get("name").(string),
+			Value: d.Get("value").(string),
+		})
+		if err != nil {
+			return errorDiag(ctx, "Unable to update device attribute "+d.Id(), err, nil)
+		}
+
+		attributeLogger.Infof("Updated attribute with ID: %s", d.Id())
+	}
+
+	return resourceDeviceAttributeRead(ctx, d, m)
+}
+
+func resourceDeviceAttributeDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*wugapi.Client)
+
+	if err := client.RemoveDeviceAttribute(ctx, d.Get("device_id").(string), deviceAttributeID(d)); err != nil {
+		return errorDiag(ctx, "Unable to remove device attribute "+d.Id(), err, nil)
+	}
+
+	d.SetId("")
+
+	return nil
+}
@@ -0,0 +1,135 @@
+package wug
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+
+	"github.com/nerimcloud/terraform-provider-wug/wugapi/wugtest"
+)
+
+func TestAccDeviceAttribute_basic(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDeviceDestroy(srv),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDeviceAttributeConfig(srv, "platform-team"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDeviceAttribute(srv, "wug_device_attribute.owner", "owner", "platform-team"),
+					testAccCheckDeviceAttribute(srv, "wug_device_attribute.cost_center", "cost_center", "CC-1234"),
+				),
+			},
+			{
+				/* By name, attribute IDs are not shown in the console. */
+				ResourceName:      "wug_device_attribute.owner",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateIdFunc: func(s *terraform.State) (string, error) {
+					return s.RootModule().Resources["wug_device.test"].Primary.ID + "/owner", nil
+				},
+			},
+		},
+	})
+}
+
+func TestAccDeviceAttribute_update(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	var id string
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDeviceDestroy(srv),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDeviceAttributeConfig(srv, "platform-team"),
+				Check:  testAccCheckDeviceID("wug_device_attribute.owner", &id),
+			},
+			{
+				Config: testAccDeviceAttributeConfig(srv, "app-team"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDeviceID("wug_device_attribute.owner", &id),
+					testAccCheckDeviceAttribute(srv, "wug_device_attribute.owner", "owner", "app-team"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccDeviceAttribute_drift(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDeviceDestroy(srv),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDeviceAttributeConfig(srv, "platform-team"),
+				Check:  testAccChangeDeviceAttribute(srv, "owner", "someone-else"),
+				/* The next apply sets the value back. */
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testAccDeviceAttributeConfig(srv, "platform-team"),
+				Check:  testAccCheckDeviceAttribute(srv, "wug_device_attribute.owner", "owner", "platform-team"),
+			},
+		},
+	})
+}
+
+func testAccCheckDeviceAttribute(srv *wugtest.Server, name, attributeName, value string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("resource not found: %s", name)
+		}
+
+		device, ok := srv.Device(rs.Primary.Attributes["device_id"])
+		if !ok {
+			return fmt.Errorf("device %s does not exist in WUG", rs.Primary.Attributes["device_id"])
+		}
+
+		for _, attribute := range device.Attributes {
+			if rs.Primary.ID == device.ID+"/"+attribute["id"].(string) {
+				if attribute["name"] != attributeName || attribute["value"] != value {
+					return fmt.Errorf("unexpected attribute %v", attribute)
+				}
+				return nil
+			}
+		}
+
+		return fmt.Errorf("attribute %s does not exist in WUG", rs.Primary.ID)
+	}
+}
+
+/* Simulates a change from the WUG console. */
+func testAccChangeDeviceAttribute(srv *wugtest.Server, name, value string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		srv.SetDeviceAttribute(s.RootModule().Resources["wug_device.test"].Primary.ID, name, value)
+		return nil
+	}
+}
+
+func testAccDeviceAttributeConfig(srv *wugtest.Server, owner string) string {
+	return testAccDeviceConfig(srv, "web-01", "Debian") + fmt.Sprintf(`
+resource "wug_device_attribute" "owner" {
+  device_id = wug_device.test.id
+  name      = "owner"
+  value     = %q
+}
+
+resource "wug_device_attribute" "cost_center" {
+  device_id = wug_device.test.id
+  name      = "cost_center"
+  value     = "CC-1234"
+}
+`, owner)
+}
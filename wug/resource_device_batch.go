@@ -0,0 +1,438 @@
+package wug
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/structure"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	"github.com/nerimcloud/terraform-provider-wug/logging"
+	"github.com/nerimcloud/terraform-provider-wug/wugapi"
+)
+
+var deviceBatchLogger = logging.New("wug_device_batch")
+
+func resourceDeviceBatch() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceDeviceBatchCreate,
+		ReadContext:   resourceDeviceBatchRead,
+		UpdateContext: resourceDeviceBatchUpdate,
+		DeleteContext: resourceDeviceBatchDelete,
+
+		CustomizeDiff: resourceDeviceBatchCustomizeDiff,
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(30 * time.Minute),
+			Read:   schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(30 * time.Minute),
+			Delete: schema.DefaultTimeout(30 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"options": {
+				Type:        schema.TypeString,
+				Description: "Set of options for applying the templates (either l2 or basic). Only used when devices are added.",
+				Required:    true,
+				ValidateFunc: validation.StringInSlice([]string{
+					"l2",
+					"basic",
+				}, true),
+			},
+			"batch_size": {
+				Type:         schema.TypeInt,
+				Description:  "Number of templates sent per call.",
+				Optional:     true,
+				Default:      100,
+				ValidateFunc: validation.IntBetween(1, 1000),
+			},
+			"device": {
+				Type:        schema.TypeSet,
+				Description: "Devices, as in wug_device, with a key. Devices are updated in place, their template_json only being used when they are added.",
+				Required:    true,
+				Elem:        &schema.Resource{Schema: batchDeviceSchema()},
+			},
+			"device_ids": {
+				Type:        schema.TypeMap,
+				Description: "IDs of the devices, by key.",
+				Computed:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"failed_devices": {
+				Type:        schema.TypeSet,
+				Description: "Keys of the devices whose last change failed. They are read back from WUG on the next refresh, so that the change is planned again.",
+				Computed:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+		},
+	}
+}
+
+// batchDeviceSchema is the schema of wug_device with a key, without options
+// nor computed attributes, which set elements cannot have. Devices are
+// updated in place, so nothing is ForceNew.
+func batchDeviceSchema() map[string]*schema.Schema {
+	attributes := resourceDevice().Schema
+	delete(attributes, "options")
+	delete(attributes, "rendered_template_json")
+
+	/* The ID is the batch's, whose updates add devices too. */
+	attributes["template_json"].DiffSuppressFunc = structure.SuppressJsonDiff
+
+	var strip func(map[string]*schema.Schema)
+	strip = func(attributes map[string]*schema.Schema) {
+		for _, attribute := range attributes {
+			attribute.ForceNew = false
+			attribute.Computed = false
+			if elem, ok := attribute.Elem.(*schema.Resource); ok {
+				strip(elem.Schema)
+			}
+		}
+	}
+	strip(attributes)
+
+	attributes["key"] = &schema.Schema{
+		Type:         schema.TypeString,
+		Description:  "Identifier of the device in the batch, unique and stable: a device whose name changes but not its key is renamed in place.",
+		Required:     true,
+		ValidateFunc: validation.StringIsNotEmpty,
+	}
+
+	return attributes
+}
+
+// batchDeviceChange is the change of a device block between two plans of a
+// batch, matched by key.
+type batchDeviceChange struct {
+	old, new map[string]interface{}
+}
+
+func (c batchDeviceChange) GetChange(key string) (interface{}, interface{}) {
+	return c.old[key], c.new[key]
+}
+
+func (c batchDeviceChange) HasChange(key string) bool {
+	o, n := c.GetChange(key)
+	if key == "template_json" && o != n {
+		return !structure.SuppressJsonDiff(key, o.(string), n.(string), nil)
+	}
+	if set, ok := o.(*schema.Set); ok {
+		return !set.Equal(n)
+	}
+
+	return !reflect.DeepEqual(o, n)
+}
+
+// devicesByKey indexes the device blocks of a batch by key.
+func devicesByKey(devices interface{}) map[string]map[string]interface{} {
+	indexed := make(map[string]map[string]interface{})
+	for _, item := range devices.(*schema.Set).List() {
+		device := item.(map[string]interface{})
+		indexed[device["key"].(string)] = device
+	}
+
+	return indexed
+}
+
+func resourceDeviceBatchCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	/* Keys are the template IDs, and the keys of device_ids. */
+	keys := make(map[string]bool)
+	for _, item := range d.Get("device").(*schema.Set).List() {
+		key := item.(map[string]interface{})["key"].(string)
+		if keys[key] {
+			return fmt.Errorf("device keys must be unique in a batch, %q is repeated", key)
+		}
+		keys[key] = true
+	}
+
+	if d.Id() == "" {
+		return nil
+	}
+	if !d.NewValueKnown("device") {
+		if err := d.SetNewComputed("failed_devices"); err != nil {
+			return err
+		}
+		return d.SetNewComputed("device_ids")
+	}
+
+	/* Devices changed in place keep their ID. New devices get theirs on
+	 * apply, which the plan shows. Devices which could not be deleted are
+	 * deleted again. */
+	o, n := d.GetChange("device")
+	oldDevices, newDevices := devicesByKey(o), devicesByKey(n)
+
+	ids := make(map[string]interface{})
+	for key, id := range d.Get("device_ids").(map[string]interface{}) {
+		if _, keep := newDevices[key]; keep {
+			ids[key] = id
+		}
+	}
+
+	if !d.HasChange("device") && len(ids) == len(d.Get("device_ids").(map[string]interface{})) {
+		return nil
+	}
+	if err := d.SetNewComputed("failed_devices"); err != nil {
+		return err
+	}
+
+	for key := range newDevices {
+		if _, ok := oldDevices[key]; !ok {
+			return d.SetNewComputed("device_ids")
+		}
+	}
+
+	return d.SetNew("device_ids", ids)
+}
+
+// applyDeviceBatch creates the given devices, batch_size templates per call.
+// It returns the IDs of the created devices by key, with one error per device
+// that could not be created. Set elements cannot be pointed at, errors name
+// the device and point at the device blocks.
+func applyDeviceBatch(ctx context.Context, client *wugapi.Client, d *schema.ResourceData, devices []interface{}) (map[string]interface{}, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	created := make(map[string]interface{})
+
+	size := d.Get("batch_size").(int)
+
+	for start := 0; start < len(devices); start += size {
+		end := start + size
+		if end > len(devices) {
+			end = len(devices)
+		}
+
+		templates := make([]wugapi.DeviceTemplate, 0, end-start)
+		for _, item := range devices[start:end] {
+			device := item.(map[string]interface{})
+			template := expandDeviceTemplate(func(key string) interface{} { return device[key] })
+			template.TemplateID = device["key"].(string)
+			templates = append(templates, template)
+		}
+
+		idMap, err := client.ApplyDeviceTemplates(ctx, []string{d.Get("options").(string)}, templates)
+
+		var failures wugapi.DeviceTemplateErrors
+		if err != nil && !errors.As(err, &failures) {
+			/* Nothing is known about this chunk, stop there rather than
+			 * flooding WUG with failing calls. */
+			return created, append(diags, errorDiag(ctx,
+				fmt.Sprintf("Unable to apply the device templates, %d devices left to create", len(devices)-start),
+				err, nil)...)
+		}
+
+		for _, entry := range idMap {
+			if entry.ResultID == "" {
+				diags = append(diags, errorDiag(ctx, "Unable to create device "+entry.TemplateID,
+					errors.New("no device ID returned"), cty.GetAttrPath("device"))...)
+				continue
+			}
+
+			created[entry.TemplateID] = entry.ResultID
+			deviceBatchLogger.Infof("Created device %s with ID: %s", entry.TemplateID, entry.ResultID)
+		}
+
+		for _, failure := range failures {
+			diags = append(diags, errorDiag(ctx, "Unable to create device "+failure.TemplateID,
+				errors.New(strings.Join(failure.Messages, ", ")), cty.GetAttrPath("device"))...)
+		}
+	}
+
+	return created, diags
+}
+
+func resourceDeviceBatchCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*wugapi.Client)
+
+	ids, diags := applyDeviceBatch(ctx, client, d, d.Get("device").(*schema.Set).List())
+	if len(ids) == 0 {
+		/* Nothing to keep track of. */
+		return diags
+	}
+
+	/* The created devices are kept track of even when others failed. The
+	 * batch is tainted then, as any resource whose creation failed:
+	 * untainting it keeps them, and plans the failed ones again. */
+	d.SetId(resource.UniqueId())
+	d.Set("device_ids", ids)
+	d.Set("failed_devices", []string{})
+
+	deviceBatchLogger.Infof("Created device batch %s with %d devices", d.Id(), len(ids))
+
+	return append(diags, resourceDeviceBatchRead(ctx, d, m)...)
+}
+
+// flattenBatchDevice returns the device block matching a template read back
+// from WUG, keeping the given key and template_json which WUG does not
+// return.
+func flattenBatchDevice(template *wugapi.DeviceTemplate, key, templateJSON string) map[string]interface{} {
+	elem := &schema.Resource{Schema: batchDeviceSchema()}
+	data := elem.Data(nil)
+	flattenDeviceTemplate(data, template)
+	data.Set("key", key)
+	data.Set("template_json", templateJSON)
+
+	device := make(map[string]interface{})
+	for name := range elem.Schema {
+		device[name] = data.Get(name)
+	}
+
+	return device
+}
+
+func resourceDeviceBatchRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*wugapi.Client)
+
+	/* Only check that the devices still exist: wug_device tracks the drift
+	 * of individual devices. Vanished devices are planned again. */
+	ids := d.Get("device_ids").(map[string]interface{})
+	failed := d.Get("failed_devices").(*schema.Set)
+	kept := schema.NewSet(d.Get("device").(*schema.Set).F, nil)
+
+	for _, item := range d.Get("device").(*schema.Set).List() {
+		device := item.(map[string]interface{})
+		key := device["key"].(string)
+
+		id, ok := ids[key]
+		if !ok {
+			continue
+		}
+
+		template, err := client.GetDeviceTemplate(ctx, id.(string))
+		if errors.Is(err, wugapi.ErrNotFound) {
+			deviceBatchLogger.Infof("Device %s of batch %s not found", id, d.Id())
+			delete(ids, key)
+			continue
+		} else if err != nil {
+			return errorDiag(ctx, "Unable to read device "+id.(string), err, nil)
+		}
+
+		/* The state holds the planned block of a device whose change
+		 * failed, keep what WUG has instead. */
+		if failed.Contains(key) {
+			device = flattenBatchDevice(template, key, device["template_json"].(string))
+		}
+		kept.Add(device)
+	}
+
+	d.Set("device", kept)
+	d.Set("device_ids", ids)
+	d.Set("failed_devices", []string{})
+
+	return nil
+}
+
+func resourceDeviceBatchUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*wugapi.Client)
+
+	o, n := d.GetChange("device")
+	oldDevices, newDevices := devicesByKey(o), devicesByKey(n)
+
+	o, _ = d.GetChange("device_ids")
+	ids := o.(map[string]interface{})
+
+	/* Terraform stores the planned devices whatever happens: devices that
+	 * could not be changed are recorded, so that the next refresh reads
+	 * them back and plans them again. */
+	var diags diag.Diagnostics
+	failed := make([]string, 0)
+
+	defer func() {
+		d.Set("device_ids", ids)
+		d.Set("failed_devices", failed)
+	}()
+
+	for key, id := range ids {
+		if _, keep := newDevices[key]; keep {
+			continue
+		}
+
+		if err := client.DeleteDevice(ctx, id.(string)); err != nil && !errors.Is(err, wugapi.ErrNotFound) {
+			diags = append(diags, errorDiag(ctx, "Unable to delete device "+key, err, nil)...)
+			continue
+		}
+		delete(ids, key)
+
+		deviceBatchLogger.Infof("Deleted device %s with ID: %s", key, id)
+	}
+
+	added := make([]interface{}, 0)
+
+	for key, device := range newDevices {
+		old, known := oldDevices[key]
+		id, ok := ids[key]
+		if !known || !ok {
+			added = append(added, device)
+			continue
+		}
+
+		change := batchDeviceChange{old, device}
+		if !hasChanges(change, keysOf(device)...) {
+			continue
+		}
+
+		if updateDiags := updateDevice(ctx, client, id.(string), change, cty.GetAttrPath("device")); updateDiags.HasError() {
+			diags = append(diags, updateDiags...)
+			failed = append(failed, key)
+			continue
+		}
+
+		deviceBatchLogger.Infof("Updated device %s with ID: %s", key, id)
+	}
+
+	created, createDiags := applyDeviceBatch(ctx, client, d, added)
+	diags = append(diags, createDiags...)
+
+	for key, id := range created {
+		ids[key] = id
+	}
+
+	return diags
+}
+
+// keysOf returns the attributes of a device block.
+func keysOf(device map[string]interface{}) []string {
+	keys := make([]string, 0, len(device))
+	for key := range device {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+func resourceDeviceBatchDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*wugapi.Client)
+
+	/* Try every device, and keep track of those left. */
+	var diags diag.Diagnostics
+	ids := d.Get("device_ids").(map[string]interface{})
+
+	for key, id := range ids {
+		if err := client.DeleteDevice(ctx, id.(string)); err != nil && !errors.Is(err, wugapi.ErrNotFound) {
+			diags = append(diags, errorDiag(ctx, "Unable to delete device "+key, err, nil)...)
+			continue
+		}
+		delete(ids, key)
+
+		deviceBatchLogger.Infof("Deleted device %s with ID: %s", key, id)
+	}
+
+	if diags.HasError() {
+		d.Set("device_ids", ids)
+		return diags
+	}
+
+	d.SetId("")
+
+	return nil
+}
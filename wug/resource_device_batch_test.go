@@ -0,0 +1,357 @@
+package wug
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+
+	"github.com/nerimcloud/terraform-provider-wug/wugapi/wugtest"
+)
+
+func TestAccDeviceBatch_basic(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	ids := make(map[string]string)
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDeviceDestroy(srv),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDeviceBatchConfig(srv,
+					testAccBatchDevice("web-01", "Debian", "10.0.0.1"),
+					testAccBatchDevice("web-02", "Debian", "10.0.0.2"),
+					testAccBatchDevice("web-03", "Debian", "10.0.0.3")),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("wug_device_batch.test", "device.#", "3"),
+					resource.TestCheckResourceAttr("wug_device_batch.test", "device_ids.%", "3"),
+					testAccCheckDeviceBatchIDs(srv, ids, "web-01", "web-02", "web-03"),
+					testAccCheckTemplateCalls(srv, 2),
+				),
+			},
+			{
+				/* web-02 changes in place, web-03 goes, web-04 comes. */
+				Config: testAccDeviceBatchConfig(srv,
+					testAccBatchDevice("web-01", "Debian", "10.0.0.1"),
+					testAccBatchDevice("web-02", "Ubuntu", "10.0.0.2"),
+					testAccBatchDevice("web-04", "Debian", "10.0.0.4")),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("wug_device_batch.test", "device_ids.%", "3"),
+					testAccCheckDeviceBatchIDs(srv, ids, "web-01", "web-02", "web-04"),
+					testAccCheckBatchDeviceOS(srv, "web-02", "Ubuntu"),
+					testAccCheckDeviceCount(srv, 3),
+					testAccCheckTemplateCalls(srv, 3),
+				),
+			},
+			{
+				/* A new active monitor is added to web-01 in place. */
+				Config: testAccDeviceBatchConfig(srv,
+					testAccBatchDeviceBlocks("web-01", "Debian", "10.0.0.1", `
+    active_monitor {
+      name     = "Ping"
+      critical = true
+    }
+`),
+					testAccBatchDevice("web-02", "Ubuntu", "10.0.0.2"),
+					testAccBatchDevice("web-04", "Debian", "10.0.0.4")),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDeviceBatchIDs(srv, ids, "web-01", "web-02", "web-04"),
+					testAccCheckBatchDeviceMonitors(srv, "web-01", 1),
+					testAccCheckDeviceCount(srv, 3),
+					testAccCheckTemplateCalls(srv, 3),
+				),
+			},
+			{
+				/* web-04 keeps its key, and is renamed in place. */
+				Config: testAccDeviceBatchConfig(srv,
+					testAccBatchDevice("web-01", "Debian", "10.0.0.1"),
+					testAccBatchDevice("web-02", "Ubuntu", "10.0.0.2"),
+					strings.Replace(testAccBatchDevice("web-04", "Debian", "10.0.0.4"), `name         = "web-04"`, `name         = "web-05"`, 1)),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDeviceBatchIDs(srv, ids, "web-01", "web-02", "web-04"),
+					testAccCheckBatchDeviceName(srv, "web-04", "web-05"),
+					testAccCheckDeviceCount(srv, 3),
+					testAccCheckTemplateCalls(srv, 3),
+				),
+			},
+		},
+	})
+}
+
+func TestAccDeviceBatch_partialFailure(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	ids := make(map[string]string)
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDeviceDestroy(srv),
+		Steps: []resource.TestStep{
+			{
+				/* web-02 fails, web-01 is kept in the tainted batch. */
+				Config: testAccDeviceBatchConfig(srv,
+					testAccBatchDevice("web-01", "Debian", "10.0.0.1"),
+					strings.Replace(testAccBatchDevice("web-02", "Debian", "10.0.0.2"), "public-v2", "missing", 1)),
+				ExpectError: regexp.MustCompile("Unable to create device web-02"),
+			},
+			{
+				/* The tainted batch is replaced, web-01 included. */
+				Config: testAccDeviceBatchConfig(srv,
+					testAccBatchDevice("web-01", "Debian", "10.0.0.1"),
+					testAccBatchDevice("web-02", "Debian", "10.0.0.2")),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDeviceBatchIDs(srv, ids, "web-01", "web-02"),
+					testAccCheckDeviceCount(srv, 2),
+					testAccCheckTemplateCalls(srv, 2),
+				),
+			},
+			{
+				/* An in-place change of web-02 fails, and leaves it as it
+				 * was in WUG. */
+				Config: testAccDeviceBatchConfig(srv,
+					testAccBatchDevice("web-01", "Debian", "10.0.0.1"),
+					strings.Replace(testAccBatchDevice("web-02", "Ubuntu", "10.0.0.2"), "public-v2", "missing", 1)),
+				ExpectError: regexp.MustCompile("Unable to assign credential missing to device"),
+			},
+			{
+				/* web-02 is read back from WUG, its properties changed
+				 * before the failure. */
+				Config: testAccDeviceBatchConfig(srv,
+					testAccBatchDevice("web-01", "Debian", "10.0.0.1"),
+					testAccBatchDevice("web-02", "Ubuntu", "10.0.0.2")),
+				PlanOnly: true,
+			},
+			{
+				Config: testAccDeviceBatchConfig(srv,
+					testAccBatchDevice("web-01", "Debian", "10.0.0.1"),
+					testAccBatchDevice("web-02", "Ubuntu", "10.0.0.2")),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDeviceBatchIDs(srv, ids, "web-01", "web-02"),
+					resource.TestCheckResourceAttr("wug_device_batch.test", "failed_devices.#", "0"),
+					testAccCheckBatchDeviceOS(srv, "web-02", "Ubuntu"),
+					testAccCheckBatchDeviceCredentials(srv, "web-02", "SNMP/public-v2"),
+				),
+			},
+			{
+				Config:      testAccDeviceBatchConfig(srv, testAccBatchDevice("web-01", "Debian", "10.0.0.1"), testAccBatchDevice("web-01", "Ubuntu", "10.0.0.2")),
+				ExpectError: regexp.MustCompile(`device keys must be unique in a batch, "web-01" is repeated`),
+			},
+		},
+	})
+}
+
+func TestAccDeviceBatch_deleteFailure(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	ids := make(map[string]string)
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDeviceDestroy(srv),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDeviceBatchConfig(srv,
+					testAccBatchDevice("web-01", "Debian", "10.0.0.1"),
+					testAccBatchDevice("web-02", "Debian", "10.0.0.2")),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDeviceBatchIDs(srv, ids, "web-01", "web-02"),
+					func(s *terraform.State) error {
+						srv.Fail(http.MethodDelete, "/devices/"+ids["web-01"], http.StatusInternalServerError, "{}")
+						return nil
+					},
+				),
+			},
+			{
+				/* web-02 is deleted all the same. */
+				Config:      testAccProviderConfig(srv),
+				ExpectError: regexp.MustCompile("Unable to delete device web-01"),
+			},
+			{
+				/* Only web-01 is left to delete. */
+				PreConfig: srv.ClearFailures,
+				Config:    testAccProviderConfig(srv),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDeviceCount(srv, 0),
+					func(s *terraform.State) error {
+						if calls := srv.Requests(http.MethodDelete, "/devices/"+ids["web-02"]); calls != 1 {
+							return fmt.Errorf("expected device web-02 to be deleted once, got %d calls", calls)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestAccDeviceBatch_disappears(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDeviceDestroy(srv),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDeviceBatchConfig(srv,
+					testAccBatchDevice("web-01", "Debian", "10.0.0.1"),
+					testAccBatchDevice("web-02", "Debian", "10.0.0.2")),
+				Check: resource.ComposeTestCheckFunc(
+					func(s *terraform.State) error {
+						srv.DeleteDevice(s.RootModule().Resources["wug_device_batch.test"].Primary.Attributes["device_ids.web-02"])
+						return nil
+					},
+				),
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+// testAccCheckDeviceBatchIDs checks that the batch holds exactly the devices
+// with the given keys, and that they exist. Devices recorded in ids by earlier
+// calls must keep their ID.
+func testAccCheckDeviceBatchIDs(srv *wugtest.Server, ids map[string]string, keys ...string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		attributes := s.RootModule().Resources["wug_device_batch.test"].Primary.Attributes
+
+		for _, key := range keys {
+			id := attributes["device_ids."+key]
+			if _, ok := srv.Device(id); !ok {
+				return fmt.Errorf("device %s (%q) does not exist in WUG", key, id)
+			}
+
+			if previous, seen := ids[key]; seen && previous != id {
+				return fmt.Errorf("device %s was replaced: ID changed from %s to %s", key, previous, id)
+			}
+			ids[key] = id
+		}
+
+		if attributes["device_ids.%"] != fmt.Sprint(len(keys)) {
+			return fmt.Errorf("expected %d devices, got %s", len(keys), attributes["device_ids.%"])
+		}
+
+		return nil
+	}
+}
+
+func testAccCheckBatchDeviceName(srv *wugtest.Server, key, name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		id := s.RootModule().Resources["wug_device_batch.test"].Primary.Attributes["device_ids."+key]
+		device, _ := srv.Device(id)
+		if device.Template["displayName"] != name {
+			return fmt.Errorf("expected device %s to be named %s, got %v", key, name, device.Template["displayName"])
+		}
+
+		return nil
+	}
+}
+
+// testAccCheckBatchDeviceMonitors checks the number of monitors of the named
+// device.
+func testAccCheckBatchDeviceMonitors(srv *wugtest.Server, name string, count int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		id := s.RootModule().Resources["wug_device_batch.test"].Primary.Attributes["device_ids."+name]
+		device, _ := srv.Device(id)
+		if len(device.Monitors) != count {
+			return fmt.Errorf("expected %d monitors on device %s, got %d", count, name, len(device.Monitors))
+		}
+
+		return nil
+	}
+}
+
+func testAccCheckBatchDeviceOS(srv *wugtest.Server, name, os string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		id := s.RootModule().Resources["wug_device_batch.test"].Primary.Attributes["device_ids."+name]
+		device, _ := srv.Device(id)
+		if device.Template["os"] != os {
+			return fmt.Errorf("expected device %s to run %s, got %v", name, os, device.Template["os"])
+		}
+
+		return nil
+	}
+}
+
+func testAccCheckBatchDeviceCredentials(srv *wugtest.Server, name string, credentials ...string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		id := s.RootModule().Resources["wug_device_batch.test"].Primary.Attributes["device_ids."+name]
+		if got := srv.DeviceCredentials(id); !reflect.DeepEqual(got, credentials) {
+			return fmt.Errorf("expected credentials %v on device %s, got %v", credentials, name, got)
+		}
+
+		return nil
+	}
+}
+
+func testAccCheckDeviceCount(srv *wugtest.Server, count int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		if ids := srv.DeviceIDs(); len(ids) != count {
+			return fmt.Errorf("expected %d devices in WUG, got %v", count, ids)
+		}
+
+		return nil
+	}
+}
+
+// testAccCheckTemplateCalls checks the number of template PATCH calls so far.
+func testAccCheckTemplateCalls(srv *wugtest.Server, count int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		if calls := srv.Requests(http.MethodPatch, "/devices/-/config/template"); calls != count {
+			return fmt.Errorf("expected %d template calls, got %d", count, calls)
+		}
+
+		return nil
+	}
+}
+
+/* testAccBatchDevice renders a device block of wug_device_batch, keyed by
+ * name. */
+func testAccBatchDevice(name, os, address string) string {
+	return testAccBatchDeviceBlocks(name, os, address, "")
+}
+
+/* testAccBatchDeviceBlocks renders a device block of wug_device_batch, with
+ * extra blocks. */
+func testAccBatchDeviceBlocks(name, os, address, blocks string) string {
+	return fmt.Sprintf(`
+  device {
+    key          = %q
+    name         = %q
+    primary_role = "Server"
+    os           = %q
+
+    interface {
+      default         = true
+      network_name    = %q
+      network_address = %q
+    }
+
+    credential {
+      type = "SNMP"
+      name = "public-v2"
+    }
+%s  }
+`, name, name, os, name, address, blocks)
+}
+
+/* testAccDeviceBatchConfig renders a batch of the given devices, sending
+ * two templates per call. */
+func testAccDeviceBatchConfig(srv *wugtest.Server, devices ...string) string {
+	return testAccProviderConfig(srv) + fmt.Sprintf(`
+resource "wug_device_batch" "test" {
+  options    = "basic"
+  batch_size = 2
+%s
+}
+`, strings.Join(devices, ""))
+}
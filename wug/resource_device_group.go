@@ -0,0 +1,203 @@
+package wug
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/nerimcloud/terraform-provider-wug/logging"
+	"github.com/nerimcloud/terraform-provider-wug/wugapi"
+)
+
+var deviceGroupLogger = logging.New("wug_device_group")
+
+func resourceDeviceGroup() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceDeviceGroupCreate,
+		ReadContext:   resourceDeviceGroupRead,
+		UpdateContext: resourceDeviceGroupUpdate,
+		DeleteContext: resourceDeviceGroupDelete,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+
+		CustomizeDiff: resourceDeviceGroupCustomizeDiff,
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+
+		Schema: withDeviceGroupParent(map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Description: "Name of the group.",
+				Required:    true,
+			},
+			"description": {
+				Type:        schema.TypeString,
+				Description: "Description of the group.",
+				Optional:    true,
+			},
+		}),
+	}
+}
+
+// withDeviceGroupParent adds the parent_id and parent_path arguments shared
+// by the group resources.
+func withDeviceGroupParent(s map[string]*schema.Schema) map[string]*schema.Schema {
+	s["parent_id"] = &schema.Schema{
+		Type:         schema.TypeString,
+		Description:  "ID of the parent group.",
+		Optional:     true,
+		Computed:     true,
+		ExactlyOneOf: []string{"parent_id", "parent_path"},
+	}
+	s["parent_path"] = &schema.Schema{
+		Type:         schema.TypeList,
+		Description:  "Names of the parent group and its ancestors, from the top-level group down.",
+		Optional:     true,
+		Computed:     true,
+		ExactlyOneOf: []string{"parent_id", "parent_path"},
+		Elem: &schema.Schema{
+			Type: schema.TypeString,
+		},
+	}
+
+	return s
+}
+
+/* Both parent references describe the same thing: when one changes, the
+ * other one is only known once the group has been moved. */
+func resourceDeviceGroupCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if d.Id() == "" {
+		return nil
+	}
+
+	if d.HasChange("parent_path") {
+		return d.SetNewComputed("parent_id")
+	}
+	if d.HasChange("parent_id") {
+		return d.SetNewComputed("parent_path")
+	}
+
+	return nil
+}
+
+// deviceGroupParentID returns the ID of the configured parent group,
+// resolving parent_path when it is the reference that changed.
+func deviceGroupParentID(ctx context.Context, client *wugapi.Client, d *schema.ResourceData) (string, error) {
+	path := make([]string, 0)
+	for _, name := range d.Get("parent_path").([]interface{}) {
+		path = append(path, name.(string))
+	}
+
+	if len(path) == 0 || !d.HasChange("parent_path") {
+		return d.Get("parent_id").(string), nil
+	}
+
+	tree, err := client.GetDeviceGroupTree(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	parent, err := tree.Find(path)
+	if err != nil {
+		return "", err
+	}
+
+	return parent.ID, nil
+}
+
+func resourceDeviceGroupCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*wugapi.Client)
+
+	parentID, err := deviceGroupParentID(ctx, client, d)
+	if err != nil {
+		return errorDiag(ctx, "Unable to find the parent group", err, cty.GetAttrPath("parent_path"))
+	}
+
+	groupID, err := client.CreateDeviceGroup(ctx, parentID, wugapi.DeviceGroup{
+		Name:        d.Get("name").(string),
+		Description: d.Get("description").(string),
+	})
+	if err != nil {
+		return errorDiag(ctx, "Unable to create the device group", err, nil)
+	}
+
+	d.SetId(groupID)
+
+	deviceGroupLogger.Infof("Created device group with ID: %s", d.Id())
+
+	return resourceDeviceGroupRead(ctx, d, m)
+}
+
+func resourceDeviceGroupRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*wugapi.Client)
+
+	group, err := client.GetDeviceGroup(ctx, d.Id())
+	if errors.Is(err, wugapi.ErrNotFound) {
+		return goneDiag(d, "Device group")
+	} else if err != nil {
+		return errorDiag(ctx, "Unable to read device group "+d.Id(), err, nil)
+	}
+
+	if group.GroupType != wugapi.StaticDeviceGroup {
+		return errorDiag(ctx, "Unable to read device group "+d.Id(), errors.New("the group is not a static group, use wug_dynamic_group instead"), nil)
+	}
+
+	tree, err := client.GetDeviceGroupTree(ctx)
+	if err != nil {
+		return errorDiag(ctx, "Unable to list device groups", err, nil)
+	}
+
+	d.Set("name", group.Name)
+	d.Set("description", group.Description)
+	d.Set("parent_id", group.ParentGroupID)
+	d.Set("parent_path", tree.Path(group.ParentGroupID))
+
+	return nil
+}
+
+func resourceDeviceGroupUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*wugapi.Client)
+
+	if d.HasChanges("name", "description", "parent_id", "parent_path") {
+		parentID, err := deviceGroupParentID(ctx, client, d)
+		if err != nil {
+			return errorDiag(ctx, "Unable to find the parent group", err, cty.GetAttrPath("parent_path"))
+		}
+
+		err = client.UpdateDeviceGroup(ctx, d.Id(), wugapi.DeviceGroup{
+			ParentGroupID: parentID,
+			Name:          d.Get("name").(string),
+			Description:   d.Get("description").(string),
+		})
+		if err != nil {
+			return errorDiag(ctx, "Unable to update device group "+d.Id(), err, nil)
+		}
+
+		deviceGroupLogger.Infof("Updated device group with ID: %s", d.Id())
+	}
+
+	return resourceDeviceGroupRead(ctx, d, m)
+}
+
+func resourceDeviceGroupDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*wugapi.Client)
+
+	if err := client.DeleteDeviceGroup(ctx, d.Id()); err != nil {
+		return errorDiag(ctx, "Unable to delete device group "+d.Id(), err, nil)
+	}
+
+	d.SetId("")
+
+	return nil
+}
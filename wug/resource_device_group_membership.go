@@ -0,0 +1,128 @@
+package wug
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/nerimcloud/terraform-provider-wug/logging"
+	"github.com/nerimcloud/terraform-provider-wug/wugapi"
+)
+
+var membershipLogger = logging.New("wug_device_group_membership")
+
+func resourceDeviceGroupMembership() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceDeviceGroupMembershipCreate,
+		ReadContext:   resourceDeviceGroupMembershipRead,
+		DeleteContext: resourceDeviceGroupMembershipDelete,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceDeviceGroupMembershipImport,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"device_id": {
+				Type:        schema.TypeString,
+				Description: "ID of the device.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"group_id": {
+				Type:        schema.TypeString,
+				Description: "ID of the static group the device is a member of.",
+				Required:    true,
+				ForceNew:    true,
+			},
+		},
+	}
+}
+
+func resourceDeviceGroupMembershipCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*wugapi.Client)
+
+	deviceID := d.Get("device_id").(string)
+	groupID := d.Get("group_id").(string)
+
+	if err := client.UpdateDeviceGroupMembers(ctx, groupID, []string{deviceID}, nil); err != nil {
+		/* WUG answers 404 for an unknown group. */
+		var path cty.Path
+		if errors.Is(err, wugapi.ErrNotFound) {
+			path = cty.GetAttrPath("group_id")
+		}
+		return errorDiag(ctx, "Unable to add device "+deviceID+" to group "+groupID, err, path)
+	}
+
+	d.SetId(deviceID + "/" + groupID)
+
+	membershipLogger.Infof("Added device %s to group %s", deviceID, groupID)
+
+	return resourceDeviceGroupMembershipRead(ctx, d, m)
+}
+
+func resourceDeviceGroupMembershipRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*wugapi.Client)
+
+	deviceID := d.Get("device_id").(string)
+	groupID := d.Get("group_id").(string)
+
+	devices, err := client.ListDeviceGroupDevices(ctx, groupID)
+	if errors.Is(err, wugapi.ErrNotFound) {
+		return goneDiag(d, "Device group membership")
+	} else if err != nil {
+		return errorDiag(ctx, "Unable to list the devices of group "+groupID, err, nil)
+	}
+
+	for _, device := range devices {
+		if device.ID == deviceID {
+			return nil
+		}
+	}
+
+	return goneDiag(d, "Device group membership")
+}
+
+// resourceDeviceGroupMembershipImport splits a "<deviceId>/<groupId>" import
+// ID.
+func resourceDeviceGroupMembershipImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	parts := strings.SplitN(d.Id(), "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("unexpected import ID %q, expected <deviceId>/<groupId>", d.Id())
+	}
+
+	d.Set("device_id", parts[0])
+	d.Set("group_id", parts[1])
+
+	return []*schema.ResourceData{d}, nil
+}
+
+func resourceDeviceGroupMembershipDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*wugapi.Client)
+
+	deviceID := d.Get("device_id").(string)
+	groupID := d.Get("group_id").(string)
+
+	/* A deleted group has no member left to remove. */
+	err := client.UpdateDeviceGroupMembers(ctx, groupID, nil, []string{deviceID})
+	if err != nil && !errors.Is(err, wugapi.ErrNotFound) {
+		return errorDiag(ctx, "Unable to remove device "+deviceID+" from group "+groupID, err, nil)
+	}
+
+	d.SetId("")
+
+	membershipLogger.Infof("Removed device %s from group %s", deviceID, groupID)
+
+	return nil
+}
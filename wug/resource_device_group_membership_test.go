@@ -0,0 +1,120 @@
+package wug
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+
+	"github.com/nerimcloud/terraform-provider-wug/wugapi/wugtest"
+)
+
+func TestAccDeviceGroupMembership_basic(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	var deviceID, groupID string
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDeviceDestroy(srv),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDeviceGroupMembershipConfig(srv),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDeviceID("wug_device.test", &deviceID),
+					testAccCheckDeviceID("wug_device_group.app", &groupID),
+					testAccCheckMembershipExists(srv, "wug_device_group_membership.test"),
+				),
+				/* The follow-up plan checks that the device picks the
+				 * membership up without a diff. */
+			},
+			{
+				ResourceName:      "wug_device_group_membership.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				/* Dropping the membership keeps the device. */
+				Config: testAccDeviceGroupsConfig(srv, "web-01", "Debian", "") + testAccDeviceGroupAppConfig(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDeviceID("wug_device.test", &deviceID),
+					testAccCheckGroupMembers(srv, groupID),
+				),
+			},
+		},
+	})
+}
+
+func TestAccDeviceGroupMembership_disappears(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDeviceDestroy(srv),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDeviceGroupMembershipConfig(srv),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckMembershipExists(srv, "wug_device_group_membership.test"),
+					testAccRemoveMembership(srv, "wug_device_group_membership.test"),
+				),
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func testAccCheckMembershipExists(srv *wugtest.Server, name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("resource not found: %s", name)
+		}
+
+		deviceID := rs.Primary.Attributes["device_id"]
+		groupID := rs.Primary.Attributes["group_id"]
+
+		if rs.Primary.ID != deviceID+"/"+groupID {
+			return fmt.Errorf("unexpected ID %s", rs.Primary.ID)
+		}
+
+		for _, id := range srv.GroupMembers(groupID) {
+			if id == deviceID {
+				return nil
+			}
+		}
+
+		return fmt.Errorf("device %s is not a member of group %s in WUG", deviceID, groupID)
+	}
+}
+
+/* Simulates a removal from the WUG console. */
+func testAccRemoveMembership(srv *wugtest.Server, name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		attributes := s.RootModule().Resources[name].Primary.Attributes
+		srv.RemoveGroupMember(attributes["group_id"], attributes["device_id"])
+		return nil
+	}
+}
+
+func testAccDeviceGroupAppConfig() string {
+	return fmt.Sprintf(`
+resource "wug_device_group" "app" {
+  name        = "App"
+  parent_path = [%q]
+}
+`, wugtest.RootGroupName)
+}
+
+/* The device has no groups block: its memberships are owned elsewhere. */
+func testAccDeviceGroupMembershipConfig(srv *wugtest.Server) string {
+	return testAccDeviceGroupsConfig(srv, "web-01", "Debian", "") + testAccDeviceGroupAppConfig() + `
+resource "wug_device_group_membership" "test" {
+  device_id = wug_device.test.id
+  group_id  = wug_device_group.app.id
+}
+`
+}
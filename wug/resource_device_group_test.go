@@ -0,0 +1,184 @@
+package wug
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+
+	"github.com/nerimcloud/terraform-provider-wug/wugapi/wugtest"
+)
+
+func TestAccDeviceGroup_basic(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDeviceGroupDestroy(srv),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDeviceGroupConfig(srv, "Linux", "Linux servers", "parent_id = wug_device_group.parent.id"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDeviceGroupExists(srv, "wug_device_group.parent"),
+					testAccCheckDeviceGroupExists(srv, "wug_device_group.test"),
+					resource.TestCheckResourceAttr("wug_device_group.parent", "parent_id", wugtest.RootGroupID),
+					resource.TestCheckResourceAttr("wug_device_group.test", "name", "Linux"),
+					resource.TestCheckResourceAttr("wug_device_group.test", "description", "Linux servers"),
+					resource.TestCheckResourceAttrPair("wug_device_group.test", "parent_id", "wug_device_group.parent", "id"),
+					resource.TestCheckResourceAttr("wug_device_group.test", "parent_path.#", "2"),
+					resource.TestCheckResourceAttr("wug_device_group.test", "parent_path.0", wugtest.RootGroupName),
+					resource.TestCheckResourceAttr("wug_device_group.test", "parent_path.1", "Datacenter"),
+				),
+			},
+			{
+				ResourceName:      "wug_device_group.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccDeviceGroup_update(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	var id string
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDeviceGroupDestroy(srv),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDeviceGroupConfig(srv, "Linux", "", "parent_id = wug_device_group.parent.id"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDeviceGroupExists(srv, "wug_device_group.test"),
+					testAccCheckDeviceID("wug_device_group.test", &id),
+				),
+			},
+			{
+				/* Rename and move the group to the top level. */
+				Config: testAccDeviceGroupConfig(srv, "Debian", "Debian servers", fmt.Sprintf("parent_path = [%q]", wugtest.RootGroupName)),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDeviceID("wug_device_group.test", &id),
+					resource.TestCheckResourceAttr("wug_device_group.test", "name", "Debian"),
+					resource.TestCheckResourceAttr("wug_device_group.test", "description", "Debian servers"),
+					resource.TestCheckResourceAttr("wug_device_group.test", "parent_id", wugtest.RootGroupID),
+					resource.TestCheckResourceAttr("wug_device_group.test", "parent_path.#", "1"),
+				),
+			},
+			{
+				/* And back under its former parent, by path this time. */
+				Config: testAccDeviceGroupConfig(srv, "Debian", "Debian servers", fmt.Sprintf("parent_path = [%q, \"Datacenter\"]", wugtest.RootGroupName)),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDeviceID("wug_device_group.test", &id),
+					resource.TestCheckResourceAttrPair("wug_device_group.test", "parent_id", "wug_device_group.parent", "id"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccDeviceGroup_disappears(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDeviceGroupDestroy(srv),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDeviceGroupConfig(srv, "Linux", "", "parent_id = wug_device_group.parent.id"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDeviceGroupExists(srv, "wug_device_group.test"),
+					testAccDeleteDeviceGroup(srv, "wug_device_group.test"),
+				),
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestAccDeviceGroup_dynamic(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDeviceGroupDestroy(srv),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDeviceGroupConfig(srv, "Linux", "", "parent_id = wug_device_group.parent.id"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDeviceGroupExists(srv, "wug_device_group.test"),
+					testAccSetDeviceGroupFilter(srv, "wug_device_group.test", testAccWindowsFilter),
+				),
+				ExpectError: regexp.MustCompile("the group is not a static group"),
+			},
+		},
+	})
+}
+
+func testAccCheckDeviceGroupExists(srv *wugtest.Server, name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("resource not found: %s", name)
+		}
+
+		if _, ok := srv.DeviceGroup(rs.Primary.ID); !ok {
+			return fmt.Errorf("device group %s does not exist in WUG", rs.Primary.ID)
+		}
+
+		return nil
+	}
+}
+
+func testAccSetDeviceGroupFilter(srv *wugtest.Server, name, filter string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		srv.SetDeviceGroupFilter(s.RootModule().Resources[name].Primary.ID, filter)
+		return nil
+	}
+}
+
+/* Simulates a deletion from the WUG console. */
+func testAccDeleteDeviceGroup(srv *wugtest.Server, name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		srv.DeleteDeviceGroup(s.RootModule().Resources[name].Primary.ID)
+		return nil
+	}
+}
+
+func testAccCheckDeviceGroupDestroy(srv *wugtest.Server) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		for _, rs := range s.RootModule().Resources {
+			if rs.Type != "wug_device_group" && rs.Type != "wug_dynamic_group" {
+				continue
+			}
+
+			if _, ok := srv.DeviceGroup(rs.Primary.ID); ok {
+				return fmt.Errorf("device group %s still exists", rs.Primary.ID)
+			}
+		}
+
+		return nil
+	}
+}
+
+func testAccDeviceGroupConfig(srv *wugtest.Server, name, description, parent string) string {
+	return testAccProviderConfig(srv) + fmt.Sprintf(`
+resource "wug_device_group" "parent" {
+  name        = "Datacenter"
+  parent_path = [%q]
+}
+
+resource "wug_device_group" "test" {
+  name        = %q
+  description = %q
+  %s
+}
+`, wugtest.RootGroupName, name, description, parent)
+}
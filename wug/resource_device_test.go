@@ -0,0 +1,666 @@
+package wug
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+
+	"github.com/nerimcloud/terraform-provider-wug/wugapi/wugtest"
+)
+
+func TestAccDevice_basic(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDeviceDestroy(srv),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDeviceConfig(srv, "web-01", "Debian"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDeviceExists(srv, "wug_device.test"),
+					resource.TestCheckResourceAttr("wug_device.test", "name", "web-01"),
+					resource.TestCheckResourceAttr("wug_device.test", "os", "Debian"),
+					resource.TestCheckResourceAttr("wug_device.test", "groups.0.name", "Linux"),
+					resource.TestCheckResourceAttr("wug_device.test", "groups.0.parents.#", "2"),
+					resource.TestCheckResourceAttr("wug_device.test", "interface.#", "1"),
+					resource.TestCheckResourceAttr("wug_device.test", "credential.#", "1"),
+					resource.TestCheckResourceAttr("wug_device.test", "active_monitor.#", "1"),
+				),
+			},
+			{
+				ResourceName:            "wug_device.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"options", "rendered_template_json"},
+			},
+		},
+	})
+}
+
+func TestAccDevice_update(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	var id string
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDeviceDestroy(srv),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDeviceConfig(srv, "web-01", "Debian"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDeviceExists(srv, "wug_device.test"),
+					testAccCheckDeviceID("wug_device.test", &id),
+				),
+			},
+			{
+				Config: testAccDeviceConfig(srv, "web-01-renamed", "Ubuntu"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDeviceID("wug_device.test", &id),
+					resource.TestCheckResourceAttr("wug_device.test", "name", "web-01-renamed"),
+					resource.TestCheckResourceAttr("wug_device.test", "os", "Ubuntu"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccDevice_groups(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	/* Moving a device only works between existing groups. */
+	rootID := srv.AddDeviceGroup("", "ROOT")
+	datacenterID := srv.AddDeviceGroup(rootID, "Datacenter")
+	linuxID := srv.AddDeviceGroup(datacenterID, "Linux")
+	debianID := srv.AddDeviceGroup(datacenterID, "Debian")
+
+	var id string
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDeviceDestroy(srv),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDeviceConfig(srv, "web-01", "Debian"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDeviceID("wug_device.test", &id),
+					testAccCheckGroupMembers(srv, linuxID, &id),
+				),
+			},
+			{
+				Config: testAccDeviceGroupsConfig(srv, "web-01", "Debian", testAccDeviceGroup("Debian")),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDeviceID("wug_device.test", &id),
+					resource.TestCheckResourceAttr("wug_device.test", "groups.#", "1"),
+					resource.TestCheckResourceAttr("wug_device.test", "groups.0.name", "Debian"),
+					testAccCheckGroupMembers(srv, linuxID),
+					testAccCheckGroupMembers(srv, debianID, &id),
+				),
+			},
+			{
+				Config:      testAccDeviceGroupsConfig(srv, "web-01", "Debian", testAccDeviceGroup("Ubuntu")),
+				ExpectError: regexp.MustCompile("Unable to add device .* to group ROOT/Datacenter/Ubuntu"),
+			},
+		},
+	})
+}
+
+func TestAccDevice_interfaces(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	var id string
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDeviceDestroy(srv),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDeviceConfig(srv, "web-01", "Debian"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDeviceID("wug_device.test", &id),
+					testAccCheckDeviceMonitors(srv, "wug_device.test", 1),
+				),
+			},
+			{
+				Config: testAccDeviceInterfacesConfig(srv, "web-01",
+					testAccDeviceInterface("web", "10.0.0.1", true, false)+
+						testAccDeviceInterface("backup", "10.0.1.1", false, false)),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDeviceID("wug_device.test", &id),
+					testAccCheckDeviceMonitors(srv, "wug_device.test", 1),
+					resource.TestCheckResourceAttr("wug_device.test", "interface.#", "2"),
+				),
+			},
+			{
+				Config: testAccDeviceInterfacesConfig(srv, "web-01",
+					testAccDeviceInterface("web", "10.0.0.1", false, false)+
+						testAccDeviceInterface("backup", "10.0.1.1", true, true)),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDeviceID("wug_device.test", &id),
+					testAccCheckDeviceMonitors(srv, "wug_device.test", 1),
+					resource.TestCheckResourceAttr("wug_device.test", "interface.#", "2"),
+					testAccCheckDefaultInterface(srv, "wug_device.test", "10.0.1.1"),
+				),
+			},
+			{
+				Config: testAccDeviceInterfacesConfig(srv, "web-01",
+					testAccDeviceInterface("backup", "10.0.1.1", true, true)),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDeviceID("wug_device.test", &id),
+					testAccCheckDeviceMonitors(srv, "wug_device.test", 1),
+					resource.TestCheckResourceAttr("wug_device.test", "interface.#", "1"),
+				),
+			},
+			{
+				Config: testAccDeviceInterfacesConfig(srv, "web-01",
+					testAccDeviceInterface("web", "10.0.0.1", true, false)+
+						testAccDeviceInterface("backup", "10.0.1.1", true, true)),
+				ExpectError: regexp.MustCompile("only one interface can be the default one"),
+			},
+		},
+	})
+}
+
+func TestAccDevice_credentials(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	var id string
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDeviceDestroy(srv),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDeviceConfig(srv, "web-01", "Debian"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDeviceID("wug_device.test", &id),
+					testAccCheckDeviceCredentials(srv, "wug_device.test", "SNMP/public-v2"),
+				),
+			},
+			{
+				Config: testAccDeviceCredentialsConfig(srv, "web-01",
+					testAccDeviceCredential("SNMPv3", "private-v3")+
+						testAccDeviceCredential("Windows", "domain-admin")),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDeviceID("wug_device.test", &id),
+					testAccCheckDeviceMonitors(srv, "wug_device.test", 1),
+					testAccCheckDeviceCredentials(srv, "wug_device.test", "SNMPv3/private-v3", "Windows/domain-admin"),
+					resource.TestCheckResourceAttr("wug_device.test", "credential.#", "2"),
+				),
+			},
+			{
+				Config: testAccDeviceCredentialsConfig(srv, "web-01",
+					testAccDeviceCredential("SNMPv3", "private-v3")),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDeviceID("wug_device.test", &id),
+					testAccCheckDeviceCredentials(srv, "wug_device.test", "SNMPv3/private-v3"),
+				),
+			},
+			{
+				Config: testAccDeviceCredentialsConfig(srv, "web-01",
+					testAccDeviceCredential("SNMPv3", "missing")),
+				ExpectError: regexp.MustCompile(`credential "missing" of type SNMPv3 not found in the credential library`),
+			},
+			{
+				Config: testAccDeviceCredentialsConfig(srv, "web-01",
+					testAccDeviceCredential("SNMP", "public-v2")+
+						testAccDeviceCredential("snmp", "public-v2")),
+				ExpectError: regexp.MustCompile("only one credential per type"),
+			},
+		},
+	})
+}
+
+func TestAccDevice_monitors(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	var id string
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDeviceDestroy(srv),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDeviceMonitorsConfig(srv, testAccDeviceActiveMonitor("HTTP Content Scan", "http://web-01/", "home page")+`
+  performance_monitor {
+    name = "CPU Utilization"
+  }
+`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDeviceID("wug_device.test", &id),
+					testAccCheckDeviceMonitors(srv, "wug_device.test", 2),
+					testAccCheckDeviceMonitorComment(srv, "wug_device.test", "HTTP Content Scan", "home page"),
+					resource.TestCheckResourceAttr("wug_device.test", "performance_monitor.#", "1"),
+				),
+			},
+			{
+				Config: testAccDeviceMonitorsConfig(srv, testAccDeviceActiveMonitor("HTTP Content Scan", "http://web-01/", "front page")),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDeviceID("wug_device.test", &id),
+					testAccCheckDeviceMonitors(srv, "wug_device.test", 2),
+					testAccCheckDeviceMonitorComment(srv, "wug_device.test", "HTTP Content Scan", "front page"),
+					resource.TestCheckResourceAttr("wug_device.test", "active_monitor.#", "2"),
+					resource.TestCheckResourceAttr("wug_device.test", "performance_monitor.#", "0"),
+				),
+			},
+			{
+				Config: testAccDeviceMonitorsConfig(srv, testAccDeviceActiveMonitor("HTTP Content Scan", "http://web-01/status", "status page")),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDeviceID("wug_device.test", &id),
+					testAccCheckDeviceMonitors(srv, "wug_device.test", 2),
+					testAccCheckDeviceMonitorComment(srv, "wug_device.test", "HTTP Content Scan", "status page"),
+				),
+			},
+			{
+				Config:      testAccDeviceMonitorsConfig(srv, testAccDeviceActiveMonitor("Missing", "", "")),
+				ExpectError: regexp.MustCompile("Unable to add monitor Missing"),
+			},
+			{
+				Config: testAccDeviceMonitorsConfig(srv, ""),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDeviceID("wug_device.test", &id),
+					testAccCheckDeviceMonitors(srv, "wug_device.test", 1),
+					resource.TestCheckResourceAttr("wug_device.test", "active_monitor.#", "1"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccDevice_disappears(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDeviceDestroy(srv),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDeviceConfig(srv, "web-01", "Debian"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDeviceExists(srv, "wug_device.test"),
+					testAccDeleteDevice(srv, "wug_device.test"),
+				),
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestAccDevice_templateError(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	srv.Fail(http.MethodPatch, "/devices/-/config/template", http.StatusBadRequest, `{"error":{"message":"Invalid template"}}`)
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDeviceDestroy(srv),
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccDeviceConfig(srv, "web-01", "Debian"),
+				ExpectError: regexp.MustCompile("Invalid template"),
+			},
+		},
+	})
+}
+
+func TestAccDevice_noDeviceID(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	srv.Fail(http.MethodPatch, "/devices/-/config/template", http.StatusOK, `{"data":{"idMap":[{"templateId":"0","resultId":""}]}}`)
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDeviceDestroy(srv),
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccDeviceConfig(srv, "web-01", "Debian"),
+				ExpectError: regexp.MustCompile("no device ID returned for template web-01"),
+			},
+		},
+	})
+}
+
+func TestAccDevice_templateJSON(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	/* The exported template has a stale name, notes and a timeout on the
+	 * interface that the typed arguments do not cover. */
+	templateJSON := `
+  template_json = jsonencode({
+    displayName = "exported"
+    notes       = "Imported from the console"
+    interfaces  = [{ networkAddress = "10.0.0.1", networkName = "web", timeout = 5 }]
+  })
+`
+
+	var id string
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDeviceDestroy(srv),
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccDeviceBlocksConfig(srv, "web-01", "Debian", `  template_json = "[]"`),
+				ExpectError: regexp.MustCompile(`"template_json" must be a JSON object`),
+			},
+			{
+				Config: testAccDeviceBlocksConfig(srv, "web-01", "Debian", templateJSON+
+					testAccDeviceInterface("web", "10.0.0.1", true, false)),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDeviceID("wug_device.test", &id),
+					testAccCheckDeviceTemplate(srv, "wug_device.test", "displayName", "web-01"),
+					testAccCheckDeviceTemplate(srv, "wug_device.test", "notes", "Imported from the console"),
+					testAccCheckDefaultInterface(srv, "wug_device.test", "10.0.0.1"),
+					resource.TestMatchResourceAttr("wug_device.test", "rendered_template_json", regexp.MustCompile(`"displayName":"web-01"`)),
+					resource.TestMatchResourceAttr("wug_device.test", "rendered_template_json", regexp.MustCompile(`"notes":"Imported from the console"`)),
+					resource.TestMatchResourceAttr("wug_device.test", "rendered_template_json", regexp.MustCompile(`"timeout":5`)),
+				),
+			},
+			{
+				/* Reformatting the JSON plans nothing. */
+				Config: testAccDeviceBlocksConfig(srv, "web-01", "Debian", `
+  template_json = <<EOT
+{
+  "notes": "Imported from the console",
+  "displayName": "exported",
+  "interfaces": [{"timeout": 5, "networkName": "web", "networkAddress": "10.0.0.1"}]
+}
+EOT
+`+testAccDeviceInterface("web", "10.0.0.1", true, false)),
+				PlanOnly: true,
+			},
+			{
+				/* In-place changes do not go through the template, which
+				 * stays as sent on creation. */
+				Config: testAccDeviceBlocksConfig(srv, "web-01", "Ubuntu", templateJSON+
+					testAccDeviceInterface("web", "10.0.0.1", true, false)),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDeviceID("wug_device.test", &id),
+					testAccCheckDeviceTemplate(srv, "wug_device.test", "os", "Ubuntu"),
+					resource.TestMatchResourceAttr("wug_device.test", "rendered_template_json", regexp.MustCompile(`"os":"Debian"`)),
+					resource.TestMatchResourceAttr("wug_device.test", "rendered_template_json", regexp.MustCompile(`"notes":"Imported from the console"`)),
+				),
+			},
+			{
+				/* Only used on creation, a changed template plans nothing. */
+				Config: testAccDeviceBlocksConfig(srv, "web-01", "Ubuntu", `
+  template_json = jsonencode({ notes = "Edited" })
+`+testAccDeviceInterface("web", "10.0.0.1", true, false)),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func testAccCheckDeviceExists(srv *wugtest.Server, name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("resource not found: %s", name)
+		}
+
+		if _, ok := srv.Device(rs.Primary.ID); !ok {
+			return fmt.Errorf("device %s does not exist in WUG", rs.Primary.ID)
+		}
+
+		return nil
+	}
+}
+
+// testAccCheckDeviceID records the device ID on first call, and then checks
+// that it did not change, i.e. that the device was not replaced.
+func testAccCheckDeviceID(name string, id *string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		current := s.RootModule().Resources[name].Primary.ID
+
+		if *id == "" {
+			*id = current
+		} else if *id != current {
+			return fmt.Errorf("device was replaced: ID changed from %s to %s", *id, current)
+		}
+
+		return nil
+	}
+}
+
+// testAccCheckDeviceMonitors checks the number of active monitors assigned
+// to a device, e.g. that they survived an update.
+func testAccCheckDeviceMonitors(srv *wugtest.Server, name string, count int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		device, ok := srv.Device(s.RootModule().Resources[name].Primary.ID)
+		if !ok {
+			return fmt.Errorf("device %s does not exist in WUG", name)
+		}
+
+		active := 0
+		for _, monitor := range device.Monitors {
+			if monitor["type"] == "active" {
+				active++
+			}
+		}
+		if active != count {
+			return fmt.Errorf("device %s has %d active monitors, expected %d", name, active, count)
+		}
+
+		return nil
+	}
+}
+
+// testAccCheckDeviceMonitorComment checks the comment of the assignment of
+// an active monitor of a device.
+func testAccCheckDeviceMonitorComment(srv *wugtest.Server, name, monitorName, comment string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		device, ok := srv.Device(s.RootModule().Resources[name].Primary.ID)
+		if !ok {
+			return fmt.Errorf("device %s does not exist in WUG", name)
+		}
+
+		for _, monitor := range device.Monitors {
+			if monitor["monitorTypeName"] != monitorName {
+				continue
+			}
+
+			active, _ := monitor["active"].(wugtest.Object)
+			if active["comment"] != comment {
+				return fmt.Errorf("monitor %s of device %s has comment %v, expected %q", monitorName, name, active["comment"], comment)
+			}
+			return nil
+		}
+
+		return fmt.Errorf("monitor %s is not assigned to device %s", monitorName, name)
+	}
+}
+
+// testAccCheckDefaultInterface checks which interface of a device is the
+// default one.
+func testAccCheckDefaultInterface(srv *wugtest.Server, name, networkAddress string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		device, ok := srv.Device(s.RootModule().Resources[name].Primary.ID)
+		if !ok {
+			return fmt.Errorf("device %s does not exist in WUG", name)
+		}
+
+		for _, iface := range device.Interfaces {
+			if iface["defaultInterface"] == true && iface["networkAddress"] != networkAddress {
+				return fmt.Errorf("device %s has default interface %v, expected %s", name, iface["networkAddress"], networkAddress)
+			}
+		}
+
+		return nil
+	}
+}
+
+// testAccCheckDeviceTemplate checks a field of the template WUG holds for a
+// device.
+func testAccCheckDeviceTemplate(srv *wugtest.Server, name, key string, want interface{}) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		device, ok := srv.Device(s.RootModule().Resources[name].Primary.ID)
+		if !ok {
+			return fmt.Errorf("device %s does not exist in WUG", name)
+		}
+
+		if got := device.Template[key]; got != want {
+			return fmt.Errorf("device %s has %s %v, expected %v", name, key, got, want)
+		}
+
+		return nil
+	}
+}
+
+// testAccCheckDeviceCredentials checks the "<type>/<name>" of the
+// credentials assigned to a device, sorted by credential ID.
+func testAccCheckDeviceCredentials(srv *wugtest.Server, name string, want ...string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		got := srv.DeviceCredentials(s.RootModule().Resources[name].Primary.ID)
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			return fmt.Errorf("device %s has credentials %v, expected %v", name, got, want)
+		}
+
+		return nil
+	}
+}
+
+// testAccCheckGroupMembers checks the members of a group, given as
+// pointers to IDs recorded by earlier checks.
+func testAccCheckGroupMembers(srv *wugtest.Server, groupID string, deviceIDs ...*string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		want := make([]string, 0, len(deviceIDs))
+		for _, id := range deviceIDs {
+			want = append(want, *id)
+		}
+
+		if got := srv.GroupMembers(groupID); fmt.Sprint(got) != fmt.Sprint(want) {
+			return fmt.Errorf("group %s has members %v, expected %v", groupID, got, want)
+		}
+
+		return nil
+	}
+}
+
+/* Simulates a deletion from the WUG console. */
+func testAccDeleteDevice(srv *wugtest.Server, name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		srv.DeleteDevice(s.RootModule().Resources[name].Primary.ID)
+		return nil
+	}
+}
+
+func testAccCheckDeviceDestroy(srv *wugtest.Server) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		if ids := srv.DeviceIDs(); len(ids) != 0 {
+			return fmt.Errorf("devices still exist: %v", ids)
+		}
+
+		return nil
+	}
+}
+
+func testAccDeviceConfig(srv *wugtest.Server, name, os string) string {
+	return testAccDeviceGroupsConfig(srv, name, os, testAccDeviceGroup("Linux"))
+}
+
+/* testAccDeviceGroup renders a groups block for ROOT/Datacenter/<name>. */
+func testAccDeviceGroup(name string) string {
+	return fmt.Sprintf(`
+  groups {
+    name    = %q
+    parents = ["ROOT", "Datacenter"]
+  }
+`, name)
+}
+
+/* testAccDeviceInterface renders an interface block. */
+func testAccDeviceInterface(networkName, networkAddress string, isDefault, pollUsingNetworkName bool) string {
+	return fmt.Sprintf(`
+  interface {
+    default                 = %t
+    poll_using_network_name = %t
+    network_name            = %q
+    network_address         = %q
+  }
+`, isDefault, pollUsingNetworkName, networkName, networkAddress)
+}
+
+/* testAccDeviceCredential renders a credential block. */
+func testAccDeviceCredential(credentialType, name string) string {
+	return fmt.Sprintf(`
+  credential {
+    type = %q
+    name = %q
+  }
+`, credentialType, name)
+}
+
+/* testAccDeviceGroupsConfig is testAccDeviceConfig with the given groups
+ * blocks, or none. */
+func testAccDeviceGroupsConfig(srv *wugtest.Server, name, os, groups string) string {
+	return testAccDeviceBlocksConfig(srv, name, os, groups+
+		testAccDeviceInterface("web", "10.0.0.1", true, false)+
+		testAccDeviceCredential("SNMP", "public-v2"))
+}
+
+/* testAccDeviceInterfacesConfig is testAccDeviceConfig with the given
+ * interface blocks. */
+func testAccDeviceInterfacesConfig(srv *wugtest.Server, name, interfaces string) string {
+	return testAccDeviceBlocksConfig(srv, name, "Debian", testAccDeviceGroup("Linux")+
+		interfaces+
+		testAccDeviceCredential("SNMP", "public-v2"))
+}
+
+/* testAccDeviceCredentialsConfig is testAccDeviceConfig with the given
+ * credential blocks. */
+func testAccDeviceCredentialsConfig(srv *wugtest.Server, name, credentials string) string {
+	return testAccDeviceBlocksConfig(srv, name, "Debian", testAccDeviceGroup("Linux")+
+		testAccDeviceInterface("web", "10.0.0.1", true, false)+
+		credentials)
+}
+
+/* testAccDeviceBlocksConfig renders a device with the given groups,
+ * interface and credential blocks. */
+func testAccDeviceActiveMonitor(name, argument, comment string) string {
+	return fmt.Sprintf(`
+  active_monitor {
+    name     = %q
+    argument = %q
+    comment  = %q
+  }
+`, name, argument, comment)
+}
+
+func testAccDeviceMonitorsConfig(srv *wugtest.Server, monitors string) string {
+	return testAccDeviceBlocksConfig(srv, "web-01", "Debian", testAccDeviceGroup("Linux")+
+		testAccDeviceInterface("web", "10.0.0.1", true, false)+monitors)
+}
+
+func testAccDeviceBlocksConfig(srv *wugtest.Server, name, os, blocks string) string {
+	return testAccProviderConfig(srv) + fmt.Sprintf(`
+resource "wug_device" "test" {
+  name          = %q
+  options       = "basic"
+  action_policy = "Mail Policy"
+  primary_role  = "Server"
+  os            = %q
+  brand         = "VMware, Inc."
+%s
+  active_monitor {
+    name     = "Ping"
+    critical = true
+  }
+}
+`, name, os, blocks)
+}
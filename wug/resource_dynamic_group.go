@@ -0,0 +1,165 @@
+package wug
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/nerimcloud/terraform-provider-wug/logging"
+	"github.com/nerimcloud/terraform-provider-wug/wugapi"
+)
+
+var dynamicGroupLogger = logging.New("wug_dynamic_group")
+
+func resourceDynamicGroup() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceDynamicGroupCreate,
+		ReadContext:   resourceDynamicGroupRead,
+		UpdateContext: resourceDynamicGroupUpdate,
+		DeleteContext: resourceDeviceGroupDelete,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+
+		CustomizeDiff: resourceDynamicGroupCustomizeDiff,
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+
+		Schema: withDeviceGroupParent(map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Description: "Name of the group.",
+				Required:    true,
+			},
+			"description": {
+				Type:        schema.TypeString,
+				Description: "Description of the group.",
+				Optional:    true,
+			},
+			"filter": {
+				Type:        schema.TypeString,
+				Description: "Filter selecting the devices of the group, as written in the WUG console.",
+				Required:    true,
+			},
+			"device_ids": {
+				Type:        schema.TypeList,
+				Description: "IDs of the devices matching the filter.",
+				Computed:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+		}),
+	}
+}
+
+/* The matched devices are only known once WUG applied the new filter. */
+func resourceDynamicGroupCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if d.Id() != "" && d.HasChange("filter") {
+		if err := d.SetNewComputed("device_ids"); err != nil {
+			return err
+		}
+	}
+
+	return resourceDeviceGroupCustomizeDiff(ctx, d, m)
+}
+
+func resourceDynamicGroupCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*wugapi.Client)
+
+	parentID, err := deviceGroupParentID(ctx, client, d)
+	if err != nil {
+		return errorDiag(ctx, "Unable to find the parent group", err, cty.GetAttrPath("parent_path"))
+	}
+
+	groupID, err := client.CreateDeviceGroup(ctx, parentID, wugapi.DeviceGroup{
+		Name:        d.Get("name").(string),
+		Description: d.Get("description").(string),
+		GroupType:   wugapi.DynamicDeviceGroup,
+		Filter:      d.Get("filter").(string),
+	})
+	if err != nil {
+		return errorDiag(ctx, "Unable to create the dynamic group", err, rejectedPath(err, cty.GetAttrPath("filter")))
+	}
+
+	d.SetId(groupID)
+
+	dynamicGroupLogger.Infof("Created dynamic group with ID: %s", d.Id())
+
+	return resourceDynamicGroupRead(ctx, d, m)
+}
+
+func resourceDynamicGroupRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*wugapi.Client)
+
+	group, err := client.GetDeviceGroup(ctx, d.Id())
+	if errors.Is(err, wugapi.ErrNotFound) {
+		return goneDiag(d, "Dynamic group")
+	} else if err != nil {
+		return errorDiag(ctx, "Unable to read dynamic group "+d.Id(), err, nil)
+	}
+
+	if group.GroupType != wugapi.DynamicDeviceGroup {
+		return errorDiag(ctx, "Unable to read dynamic group "+d.Id(), errors.New("the group is not a dynamic group, use wug_device_group instead"), nil)
+	}
+
+	tree, err := client.GetDeviceGroupTree(ctx)
+	if err != nil {
+		return errorDiag(ctx, "Unable to list device groups", err, nil)
+	}
+
+	devices, err := client.ListDeviceGroupDevices(ctx, d.Id())
+	if err != nil {
+		return errorDiag(ctx, "Unable to list the devices of group "+d.Id(), err, nil)
+	}
+
+	deviceIDs := make([]string, 0, len(devices))
+	for _, device := range devices {
+		deviceIDs = append(deviceIDs, device.ID)
+	}
+
+	d.Set("name", group.Name)
+	d.Set("description", group.Description)
+	d.Set("filter", group.Filter)
+	d.Set("parent_id", group.ParentGroupID)
+	d.Set("parent_path", tree.Path(group.ParentGroupID))
+	d.Set("device_ids", deviceIDs)
+
+	return nil
+}
+
+func resourceDynamicGroupUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*wugapi.Client)
+
+	if d.HasChanges("name", "description", "filter", "parent_id", "parent_path") {
+		parentID, err := deviceGroupParentID(ctx, client, d)
+		if err != nil {
+			return errorDiag(ctx, "Unable to find the parent group", err, cty.GetAttrPath("parent_path"))
+		}
+
+		err = client.UpdateDeviceGroup(ctx, d.Id(), wugapi.DeviceGroup{
+			ParentGroupID: parentID,
+			Name:          d.Get("name").(string),
+			Description:   d.Get("description").(string),
+			GroupType:     wugapi.DynamicDeviceGroup,
+			Filter:        d.Get("filter").(string),
+		})
+		if err != nil {
+			return errorDiag(ctx, "Unable to update dynamic group "+d.Id(), err, rejectedPath(err, cty.GetAttrPath("filter")))
+		}
+
+		dynamicGroupLogger.Infof("Updated dynamic group with ID: %s", d.Id())
+	}
+
+	return resourceDynamicGroupRead(ctx, d, m)
+}
@@ -0,0 +1,232 @@
+package wug
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+
+	"github.com/nerimcloud/terraform-provider-wug/wugapi/wugtest"
+)
+
+const testAccWindowsFilter = "role = 'Windows Server'"
+
+/* testAccDynamicGroupDevices seeds two Windows servers in different subnets
+ * and a Linux one. */
+func testAccDynamicGroupDevices(srv *wugtest.Server) {
+	for _, device := range []struct{ name, role, address string }{
+		{"win-01", "Windows Server", "10.0.1.10"},
+		{"win-02", "Windows Server", "10.0.2.10"},
+		{"web-01", "Linux Server", "10.0.1.20"},
+	} {
+		srv.AddDevice(wugtest.Object{
+			"displayName": device.name,
+			"primaryRole": device.role,
+			"interfaces": []interface{}{
+				wugtest.Object{"defaultInterface": true, "networkAddress": device.address, "networkName": device.name},
+			},
+		})
+	}
+}
+
+func TestAccDynamicGroup_basic(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	testAccDynamicGroupDevices(srv)
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDeviceGroupDestroy(srv),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDynamicGroupConfig(srv, testAccWindowsFilter),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDeviceGroupExists(srv, "wug_dynamic_group.test"),
+					resource.TestCheckResourceAttr("wug_dynamic_group.test", "name", "Windows servers"),
+					resource.TestCheckResourceAttr("wug_dynamic_group.test", "filter", testAccWindowsFilter),
+					resource.TestCheckResourceAttr("wug_dynamic_group.test", "parent_id", wugtest.RootGroupID),
+					resource.TestCheckResourceAttr("wug_dynamic_group.test", "device_ids.#", "2"),
+				),
+			},
+			{
+				ResourceName:      "wug_dynamic_group.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				/* Once the group exists, monitors can be assigned to each
+				 * matched device. The SDK test harness does not support
+				 * for_each instances, count works the same way. */
+				Config: testAccDynamicGroupConfig(srv, testAccWindowsFilter) + `
+data "wug_monitor" "ping" {
+  type   = "active"
+  search = "Ping"
+}
+
+resource "wug_monitor" "ping" {
+  count = length(wug_dynamic_group.test.device_ids)
+
+  device_id             = wug_dynamic_group.test.device_ids[count.index]
+  type                  = "active"
+  monitor_type_class_id = data.wug_monitor.ping.class_id
+  monitor_type_id       = data.wug_monitor.ping.id
+  monitor_type_name     = data.wug_monitor.ping.monitor_name
+
+  active {
+    comment = "group ping"
+  }
+}
+`,
+				Check: testAccCheckDynamicGroupMonitors(srv, "wug_dynamic_group.test"),
+			},
+		},
+	})
+}
+
+func TestAccDynamicGroup_update(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	testAccDynamicGroupDevices(srv)
+
+	var id string
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDeviceGroupDestroy(srv),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDynamicGroupConfig(srv, testAccWindowsFilter),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDeviceID("wug_dynamic_group.test", &id),
+					resource.TestCheckResourceAttr("wug_dynamic_group.test", "device_ids.#", "2"),
+				),
+			},
+			{
+				Config: testAccDynamicGroupConfig(srv, testAccWindowsFilter+" AND networkAddress LIKE '10.0.1.%'"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDeviceID("wug_dynamic_group.test", &id),
+					resource.TestCheckResourceAttr("wug_dynamic_group.test", "device_ids.#", "1"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccDynamicGroup_rename(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	testAccDynamicGroupDevices(srv)
+
+	var id string
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDeviceGroupDestroy(srv),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDynamicGroupConfig(srv, testAccWindowsFilter),
+				Check:  testAccCheckDeviceID("wug_dynamic_group.test", &id),
+			},
+			{
+				Config: testAccDynamicGroupNamedConfig(srv, "Windows hosts", testAccWindowsFilter),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDeviceID("wug_dynamic_group.test", &id),
+					testAccCheckDynamicGroupFilter(srv, "wug_dynamic_group.test", testAccWindowsFilter),
+					resource.TestCheckResourceAttr("wug_dynamic_group.test", "name", "Windows hosts"),
+					resource.TestCheckResourceAttr("wug_dynamic_group.test", "device_ids.#", "2"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccDynamicGroup_invalidFilter(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDeviceGroupDestroy(srv),
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccDynamicGroupConfig(srv, "role is Windows"),
+				ExpectError: regexp.MustCompile("invalid filter"),
+			},
+		},
+	})
+}
+
+func TestAccDynamicGroup_disappears(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDeviceGroupDestroy(srv),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDynamicGroupConfig(srv, testAccWindowsFilter),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDeviceGroupExists(srv, "wug_dynamic_group.test"),
+					testAccDeleteDeviceGroup(srv, "wug_dynamic_group.test"),
+				),
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func testAccCheckDynamicGroupFilter(srv *wugtest.Server, name, filter string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		group, ok := srv.DeviceGroup(s.RootModule().Resources[name].Primary.ID)
+		if !ok {
+			return fmt.Errorf("device group %s does not exist in WUG", name)
+		}
+		if group.Filter != filter {
+			return fmt.Errorf("expected filter %q on %s, got %q", filter, name, group.Filter)
+		}
+
+		return nil
+	}
+}
+
+/* Every matched device has exactly one monitor assignment. */
+func testAccCheckDynamicGroupMonitors(srv *wugtest.Server, name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		attributes := s.RootModule().Resources[name].Primary.Attributes
+
+		for i := 0; i < 2; i++ {
+			id := attributes[fmt.Sprintf("device_ids.%d", i)]
+
+			device, ok := srv.Device(id)
+			if !ok {
+				return fmt.Errorf("device %s does not exist in WUG", id)
+			}
+			if len(device.Monitors) != 1 {
+				return fmt.Errorf("device %s has %d monitors, expected 1", id, len(device.Monitors))
+			}
+		}
+
+		return nil
+	}
+}
+
+func testAccDynamicGroupConfig(srv *wugtest.Server, filter string) string {
+	return testAccDynamicGroupNamedConfig(srv, "Windows servers", filter)
+}
+
+func testAccDynamicGroupNamedConfig(srv *wugtest.Server, name, filter string) string {
+	return testAccProviderConfig(srv) + fmt.Sprintf(`
+resource "wug_dynamic_group" "test" {
+  name        = %q
+  description = "Every Windows server"
+  filter      = %q
+  parent_path = [%q]
+}
+`, name, filter, wugtest.RootGroupName)
+}
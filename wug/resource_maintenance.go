@@ -0,0 +1,336 @@
+package wug
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	"github.com/nerimcloud/terraform-provider-wug/logging"
+	"github.com/nerimcloud/terraform-provider-wug/wugapi"
+)
+
+var maintenanceLogger = logging.New("wug_maintenance")
+
+func resourceMaintenance() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceMaintenanceCreate,
+		ReadContext:   resourceMaintenanceRead,
+		UpdateContext: resourceMaintenanceUpdate,
+		DeleteContext: resourceMaintenanceDelete,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceMaintenanceImport,
+		},
+
+		CustomizeDiff: resourceMaintenanceCustomizeDiff,
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"device_id": {
+				Type:         schema.TypeString,
+				Description:  "ID of the device to put in maintenance.",
+				Optional:     true,
+				ForceNew:     true,
+				ExactlyOneOf: []string{"device_id", "group_id"},
+			},
+			"group_id": {
+				Type:         schema.TypeString,
+				Description:  "ID of the group to put in maintenance.",
+				Optional:     true,
+				ForceNew:     true,
+				ExactlyOneOf: []string{"device_id", "group_id"},
+			},
+			"enabled": {
+				Type:        schema.TypeBool,
+				Description: "Turn the maintenance mode on now, until end_time when set.",
+				Optional:    true,
+				Default:     false,
+			},
+			"reason": {
+				Type:        schema.TypeString,
+				Description: "Reason of the maintenance, shown in WUG.",
+				Optional:    true,
+			},
+			"end_time": {
+				Type:             schema.TypeString,
+				Description:      "RFC 3339 time at which WUG turns the maintenance mode off.",
+				Optional:         true,
+				ValidateFunc:     validation.IsRFC3339Time,
+				DiffSuppressFunc: suppressEquivalentTime,
+			},
+			"schedule": {
+				Type:        schema.TypeList,
+				Description: "Scheduled maintenance windows.",
+				Optional:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"start_time": {
+							Type:             schema.TypeString,
+							Description:      "RFC 3339 start time of the first window.",
+							Required:         true,
+							ValidateFunc:     validation.IsRFC3339Time,
+							DiffSuppressFunc: suppressEquivalentTime,
+						},
+						"end_time": {
+							Type:             schema.TypeString,
+							Description:      "RFC 3339 end time of the first window.",
+							Required:         true,
+							ValidateFunc:     validation.IsRFC3339Time,
+							DiffSuppressFunc: suppressEquivalentTime,
+						},
+						"recurrence": {
+							Type:        schema.TypeString,
+							Description: "How the window repeats: none, daily, weekly or monthly.",
+							Optional:    true,
+							Default:     wugapi.RecurrenceNone,
+							ValidateFunc: validation.StringInSlice([]string{
+								wugapi.RecurrenceNone,
+								wugapi.RecurrenceDaily,
+								wugapi.RecurrenceWeekly,
+								wugapi.RecurrenceMonthly,
+							}, false),
+						},
+					},
+				},
+			},
+			"in_maintenance": {
+				Type:        schema.TypeBool,
+				Description: "Whether the target is in maintenance now, turned on or within a window.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+/* WUG answers in UTC, whatever the offset of the configuration. */
+func suppressEquivalentTime(k, old, new string, d *schema.ResourceData) bool {
+	o, err := time.Parse(time.RFC3339, old)
+	if err != nil {
+		return false
+	}
+	n, err := time.Parse(time.RFC3339, new)
+	if err != nil {
+		return false
+	}
+
+	return o.Equal(n)
+}
+
+/* The current state is only known once WUG applied the new settings. */
+func resourceMaintenanceCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if d.Id() == "" {
+		return nil
+	}
+
+	for _, key := range []string{"enabled", "end_time", "schedule"} {
+		if d.HasChange(key) {
+			return d.SetNewComputed("in_maintenance")
+		}
+	}
+
+	return nil
+}
+
+// timeNow is the clock maintenance end times are compared with.
+var timeNow = time.Now
+
+// maintenanceEnded reports whether an RFC 3339 end time has passed.
+func maintenanceEnded(endTime string) bool {
+	end, err := time.Parse(time.RFC3339, endTime)
+
+	return err == nil && !timeNow().Before(end)
+}
+
+// maintenanceTarget returns the kind and the ID of the configured target.
+func maintenanceTarget(d *schema.ResourceData) (string, string) {
+	if id, ok := d.GetOk("group_id"); ok {
+		return wugapi.MaintenanceGroup, id.(string)
+	}
+
+	return wugapi.MaintenanceDevice, d.Get("device_id").(string)
+}
+
+func expandMaintenanceSchedules(d *schema.ResourceData) []wugapi.MaintenanceSchedule {
+	schedules := make([]wugapi.MaintenanceSchedule, 0)
+
+	for _, item := range d.Get("schedule").([]interface{}) {
+		schedule := item.(map[string]interface{})
+		schedules = append(schedules, wugapi.MaintenanceSchedule{
+			StartUtc:   schedule["start_time"].(string),
+			EndUtc:     schedule["end_time"].(string),
+			Recurrence: schedule["recurrence"].(string),
+		})
+	}
+
+	return schedules
+}
+
+// maintenanceTargetPath points at the target of the maintenance when WUG
+// does not know it.
+func maintenanceTargetPath(err error, kind string) cty.Path {
+	if errors.Is(err, wugapi.ErrNotFound) {
+		return cty.GetAttrPath(kind + "_id")
+	}
+
+	return nil
+}
+
+func resourceMaintenanceCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*wugapi.Client)
+
+	kind, id := maintenanceTarget(d)
+
+	/* The resource owns the maintenance settings of its target, windows
+	 * set in the console are replaced. */
+	err := client.SetMaintenance(ctx, kind, id, wugapi.Maintenance{
+		Enabled: d.Get("enabled").(bool),
+		Reason:  d.Get("reason").(string),
+		EndUtc:  d.Get("end_time").(string),
+	})
+	if err != nil {
+		return errorDiag(ctx, "Unable to set the maintenance mode of "+kind+" "+id, err, maintenanceTargetPath(err, kind))
+	}
+
+	err = client.SetMaintenanceSchedules(ctx, kind, id, expandMaintenanceSchedules(d))
+	if err != nil {
+		return errorDiag(ctx, "Unable to schedule the maintenance of "+kind+" "+id, err, rejectedPath(err, cty.GetAttrPath("schedule")))
+	}
+
+	d.SetId(kind + "/" + id)
+
+	maintenanceLogger.Infof("Configured maintenance of %s", d.Id())
+
+	return resourceMaintenanceRead(ctx, d, m)
+}
+
+func resourceMaintenanceRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*wugapi.Client)
+
+	kind, id := maintenanceTarget(d)
+
+	maintenance, err := client.GetMaintenance(ctx, kind, id)
+	if errors.Is(err, wugapi.ErrNotFound) {
+		return goneDiag(d, "Maintenance target")
+	} else if err != nil {
+		return errorDiag(ctx, "Unable to read the maintenance mode of "+kind+" "+id, err, nil)
+	}
+
+	schedules, err := client.GetMaintenanceSchedules(ctx, kind, id)
+	if err != nil {
+		return errorDiag(ctx, "Unable to read the maintenance schedules of "+kind+" "+id, err, nil)
+	}
+
+	/* WUG turns the maintenance mode off once end_time passes. That is the
+	 * configured outcome rather than a drift: turning it back on would
+	 * only set an end time in the past. */
+	enabled := maintenance.Enabled
+	endTime := maintenance.EndUtc
+	if endTime == "" {
+		endTime = d.Get("end_time").(string)
+	}
+	if !enabled && d.Get("enabled").(bool) && maintenanceEnded(endTime) {
+		enabled = true
+	}
+
+	d.Set("enabled", enabled)
+	d.Set("reason", maintenance.Reason)
+	d.Set("end_time", endTime)
+	d.Set("in_maintenance", maintenance.InMaintenance)
+
+	flattened := make([]map[string]interface{}, 0)
+	for _, schedule := range schedules {
+		flattened = append(flattened, map[string]interface{}{
+			"start_time": schedule.StartUtc,
+			"end_time":   schedule.EndUtc,
+			"recurrence": schedule.Recurrence,
+		})
+	}
+
+	d.Set("schedule", flattened)
+
+	return nil
+}
+
+// resourceMaintenanceImport splits a "device/<deviceId>" or
+// "group/<groupId>" import ID.
+func resourceMaintenanceImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	parts := strings.SplitN(d.Id(), "/", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return nil, fmt.Errorf("unexpected import ID %q, expected device/<deviceId> or group/<groupId>", d.Id())
+	}
+
+	switch parts[0] {
+	case wugapi.MaintenanceDevice:
+		d.Set("device_id", parts[1])
+	case wugapi.MaintenanceGroup:
+		d.Set("group_id", parts[1])
+	default:
+		return nil, fmt.Errorf("unexpected import ID %q, expected device/<deviceId> or group/<groupId>", d.Id())
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
+func resourceMaintenanceUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*wugapi.Client)
+
+	kind, id := maintenanceTarget(d)
+
+	if d.HasChanges("enabled", "reason", "end_time") {
+		err := client.SetMaintenance(ctx, kind, id, wugapi.Maintenance{
+			Enabled: d.Get("enabled").(bool),
+			Reason:  d.Get("reason").(string),
+			EndUtc:  d.Get("end_time").(string),
+		})
+		if err != nil {
+			return errorDiag(ctx, "Unable to set the maintenance mode of "+kind+" "+id, err, maintenanceTargetPath(err, kind))
+		}
+
+		maintenanceLogger.Infof("Updated maintenance mode of %s", d.Id())
+	}
+
+	if d.HasChange("schedule") {
+		err := client.SetMaintenanceSchedules(ctx, kind, id, expandMaintenanceSchedules(d))
+		if err != nil {
+			return errorDiag(ctx, "Unable to schedule the maintenance of "+kind+" "+id, err, rejectedPath(err, cty.GetAttrPath("schedule")))
+		}
+
+		maintenanceLogger.Infof("Updated maintenance schedules of %s", d.Id())
+	}
+
+	return resourceMaintenanceRead(ctx, d, m)
+}
+
+func resourceMaintenanceDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*wugapi.Client)
+
+	kind, id := maintenanceTarget(d)
+
+	/* A deleted target has no maintenance left to turn off. */
+	err := client.SetMaintenance(ctx, kind, id, wugapi.Maintenance{Enabled: false})
+	if err == nil {
+		err = client.SetMaintenanceSchedules(ctx, kind, id, nil)
+	}
+	if err != nil && !errors.Is(err, wugapi.ErrNotFound) {
+		return errorDiag(ctx, "Unable to turn the maintenance of "+kind+" "+id+" off", err, nil)
+	}
+
+	d.SetId("")
+
+	maintenanceLogger.Infof("Turned maintenance of %s/%s off", kind, id)
+
+	return nil
+}
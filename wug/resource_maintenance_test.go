@@ -0,0 +1,230 @@
+package wug
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+
+	"github.com/nerimcloud/terraform-provider-wug/wugapi/wugtest"
+)
+
+func TestAccMaintenance_device(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDeviceDestroy(srv),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDeviceConfig(srv, "web-01", "Debian") + testAccMaintenanceDeviceConfig(true),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("wug_maintenance.test", "enabled", "true"),
+					resource.TestCheckResourceAttr("wug_maintenance.test", "reason", "deployment"),
+					resource.TestCheckResourceAttr("wug_maintenance.test", "in_maintenance", "true"),
+					testAccCheckInMaintenance(srv, "wug_device.test", wugtest.MaintenanceDevice, true),
+				),
+			},
+			{
+				ResourceName:      "wug_maintenance.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				Config: testAccDeviceConfig(srv, "web-01", "Debian") + testAccMaintenanceDeviceConfig(false),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("wug_maintenance.test", "enabled", "false"),
+					resource.TestCheckResourceAttr("wug_maintenance.test", "in_maintenance", "false"),
+					testAccCheckInMaintenance(srv, "wug_device.test", wugtest.MaintenanceDevice, false),
+				),
+			},
+		},
+	})
+}
+
+func TestAccMaintenance_groupSchedule(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	/* One window right now, and a weekly one starting tomorrow. */
+	now := time.Now().UTC().Truncate(time.Second)
+	windows := fmt.Sprintf(`
+  schedule {
+    start_time = %q
+    end_time   = %q
+  }
+
+  schedule {
+    start_time = %q
+    end_time   = %q
+    recurrence = "weekly"
+  }
+`, now.Add(-time.Hour).Format(time.RFC3339), now.Add(time.Hour).Format(time.RFC3339),
+		now.Add(24*time.Hour).Format(time.RFC3339), now.Add(26*time.Hour).Format(time.RFC3339))
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDeviceGroupDestroy(srv),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDeviceGroupAppConfig() + testAccMaintenanceGroupConfig(srv, windows),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("wug_maintenance.test", "enabled", "false"),
+					resource.TestCheckResourceAttr("wug_maintenance.test", "schedule.#", "2"),
+					resource.TestCheckResourceAttr("wug_maintenance.test", "schedule.0.recurrence", "none"),
+					resource.TestCheckResourceAttr("wug_maintenance.test", "schedule.1.recurrence", "weekly"),
+					resource.TestCheckResourceAttr("wug_maintenance.test", "in_maintenance", "true"),
+					testAccCheckInMaintenance(srv, "wug_device_group.app", wugtest.MaintenanceGroup, true),
+				),
+			},
+			{
+				/* Removing the resource clears the windows. */
+				Config: testAccDeviceGroupAppConfig() + testAccProviderConfig(srv),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckInMaintenance(srv, "wug_device_group.app", wugtest.MaintenanceGroup, false),
+					testAccCheckNoMaintenanceSchedule(srv, "wug_device_group.app"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccMaintenance_drift(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDeviceDestroy(srv),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDeviceConfig(srv, "web-01", "Debian") + testAccMaintenanceDeviceConfig(true),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckInMaintenance(srv, "wug_device.test", wugtest.MaintenanceDevice, true),
+					testAccTurnMaintenanceOff(srv, "wug_device.test"),
+				),
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestAccMaintenance_expired(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+	defer func() { timeNow = time.Now }()
+
+	config := testAccDeviceConfig(srv, "web-01", "Debian") + fmt.Sprintf(`
+resource "wug_maintenance" "test" {
+  device_id = wug_device.test.id
+  enabled   = true
+  reason    = "deployment"
+  end_time  = %q
+}
+`, time.Now().Add(time.Hour).UTC().Format(time.RFC3339))
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDeviceDestroy(srv),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check:  testAccCheckInMaintenance(srv, "wug_device.test", wugtest.MaintenanceDevice, true),
+			},
+			{
+				/* WUG turned maintenance off at end_time, nothing to
+				 * turn back on. */
+				PreConfig: func() {
+					srv.Advance(2 * time.Hour)
+					timeNow = func() time.Time { return time.Now().Add(2 * time.Hour) }
+				},
+				Config:   config,
+				PlanOnly: true,
+			},
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("wug_maintenance.test", "enabled", "true"),
+					resource.TestCheckResourceAttr("wug_maintenance.test", "in_maintenance", "false"),
+					testAccCheckInMaintenance(srv, "wug_device.test", wugtest.MaintenanceDevice, false),
+				),
+			},
+		},
+	})
+}
+
+func TestAccMaintenance_invalidTime(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(srv) + `
+resource "wug_maintenance" "test" {
+  device_id = "1"
+  enabled   = true
+  end_time  = "tomorrow"
+}
+`,
+				ExpectError: regexp.MustCompile("valid RFC3339 date"),
+			},
+		},
+	})
+}
+
+func testAccCheckInMaintenance(srv *wugtest.Server, name, kind string, want bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		id := s.RootModule().Resources[name].Primary.ID
+
+		if got := srv.InMaintenance(kind, id); got != want {
+			return fmt.Errorf("%s %s in maintenance: %t, expected %t", kind, id, got, want)
+		}
+
+		return nil
+	}
+}
+
+func testAccCheckNoMaintenanceSchedule(srv *wugtest.Server, name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		id := s.RootModule().Resources[name].Primary.ID
+
+		if schedules := srv.MaintenanceSchedules(wugtest.MaintenanceGroup, id); len(schedules) != 0 {
+			return fmt.Errorf("group %s still has maintenance windows: %v", id, schedules)
+		}
+
+		return nil
+	}
+}
+
+/* Simulates a change from the WUG console. */
+func testAccTurnMaintenanceOff(srv *wugtest.Server, name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		srv.SetMaintenance(wugtest.MaintenanceDevice, s.RootModule().Resources[name].Primary.ID, false)
+		return nil
+	}
+}
+
+func testAccMaintenanceDeviceConfig(enabled bool) string {
+	return fmt.Sprintf(`
+resource "wug_maintenance" "test" {
+  device_id = wug_device.test.id
+  enabled   = %t
+  reason    = "deployment"
+}
+`, enabled)
+}
+
+func testAccMaintenanceGroupConfig(srv *wugtest.Server, schedules string) string {
+	return testAccProviderConfig(srv) + fmt.Sprintf(`
+resource "wug_maintenance" "test" {
+  group_id = wug_device_group.app.id
+%s
+}
+`, schedules)
+}
@@ -1,49 +1,40 @@
 package wug
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
-	"log"
+	"fmt"
+	"strings"
+	"time"
 
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
-	"github.com/tidwall/gjson"
-)
-
 
-// MonitorActiveParameters is WUG's internal object.
-type MonitorActiveParameters struct {
-	CriticalOrder		int  `json:"criticalOrder,omitempty"`
-	ActionPolicyName	string `json:"actionPolicyName,omitempty"`
-	ActionPolicyId		string `json:"actionPolicyId,omitempty"`
-	Comment			string `json:"comment,omitempty"`
-	Argument		string `json:"argument,omitempty"`
-	PollingIntervalSeconds  int  `json:"pollingIntervalSeconds,omitempty"`
-	InterfaceId		string `json:"interfaceId,string,omitempty"`
-}
-
-// MonitorPerformanceParameters is WUG's internal object.
-type MonitorPerformanceParameters struct {
-	PollingIntervalMinutes int `json:"pollingIntervalMinutes,omitempty"`
-}
+	"github.com/nerimcloud/terraform-provider-wug/logging"
+	"github.com/nerimcloud/terraform-provider-wug/wugapi"
+)
 
-// MonitorParameters is WUG's internal object.
-type MonitorTemplate struct {
-	Type                string                             `json:"type,omitempty"`
-	MonitorTypeClassId  string                             `json:"monitorTypeClassId,omitempty"`
-	MonitorTypeId       string                             `json:"monitorType,omitempty"`
-	MonitorTypeName     string                             `json:"monitorTypeName,omitempty"`
-	Active              MonitorActiveParameters            `json:"active,omitempty"`
-	Performance         MonitorPerformanceParameters       `json:"performance,omitempty"`
-}
+var monitorLogger = logging.New("wug_monitor")
 
 func resourceMonitor() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceMonitorCreate,
-		Read:   resourceMonitorRead,
-		/* No Update path since all fields are ForceNew. */
-		/* Update: resourceMonitorUpdate, */
-		Delete: resourceMonitorDelete,
+		CreateContext: resourceMonitorCreate,
+		ReadContext:   resourceMonitorRead,
+		UpdateContext: resourceMonitorUpdate,
+		DeleteContext: resourceMonitorDelete,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceMonitorImport,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
 
 		Schema: map[string]*schema.Schema{
 			"device_id": {
@@ -61,6 +52,9 @@ func resourceMonitor() *schema.Resource {
 					"active",
 					"performance",
 				}, true),
+				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+					return strings.EqualFold(old, new)
+				},
 			},
 			"monitor_type_class_id": {
 				Type:        schema.TypeString,
@@ -84,8 +78,7 @@ func resourceMonitor() *schema.Resource {
 				Type:        schema.TypeList,
 				Description: "Parameters of an active monitor.",
 				Optional:    true,
-				ForceNew:    true,
-				MaxItems: 1,
+				MaxItems:    1,
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"critical_order": {
@@ -130,8 +123,7 @@ func resourceMonitor() *schema.Resource {
 				Type:        schema.TypeList,
 				Description: "Parameters of a performance monitor.",
 				Optional:    true,
-				ForceNew:    true,
-				MaxItems: 1,
+				MaxItems:    1,
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"polling_interval_minutes": {
@@ -146,14 +138,9 @@ func resourceMonitor() *schema.Resource {
 	}
 }
 
-func resourceMonitorCreate(d *schema.ResourceData, m interface{}) error {
-	wugResty := m.(*Client).Resty
-	token := m.(*Client).Token
-	config := m.(*Client).Config
-
-	var monitor MonitorTemplate
-
-	/* Build our object. */
+// buildMonitorTemplate builds the assignment object from the configuration.
+func buildMonitorTemplate(d *schema.ResourceData) wugapi.MonitorTemplate {
+	var monitor wugapi.MonitorTemplate
 
 	monitor.Type = d.Get("type").(string)
 	monitor.MonitorTypeClassId = d.Get("monitor_type_class_id").(string)
@@ -176,98 +163,112 @@ func resourceMonitorCreate(d *schema.ResourceData, m interface{}) error {
 		monitor.Performance.PollingIntervalMinutes = performanceData["polling_interval_minutes"].(int)
 	}
 
-	var deviceId = d.Get("device_id").(string)
-
-	params := monitor
+	return monitor
+}
 
-	s,_ := json.MarshalIndent(params, "", "\t")
-	log.Printf(string(s))
+func resourceMonitorCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*wugapi.Client)
 
-	resp, err := wugResty.R().
-		SetHeader("Content-Type", "application/json").
-		SetAuthToken(token).
-		SetBody(params).
-		Post(config.URL + "/devices/" + deviceId + "/monitors/-")
+	monitor := buildMonitorTemplate(d)
 
+	monitorID, err := client.AddDeviceMonitor(ctx, d.Get("device_id").(string), monitor)
 	if err != nil {
-		return err
-	} else if resp.StatusCode() != 200 {
-		return errors.New(string(resp.Body()))
-	}
-
-	monitorID := gjson.GetBytes(resp.Body(), "data.idMap.0.resultId").String()
-
-	if len(monitorID) == 0 {
-		return errors.New(string(resp.Body()))
+		/* WUG answers 404 for an unknown device. */
+		var path cty.Path
+		if errors.Is(err, wugapi.ErrNotFound) {
+			path = cty.GetAttrPath("device_id")
+		}
+		return errorDiag(ctx, "Unable to assign the monitor", err, path)
 	}
 
 	d.SetId(monitorID)
 
-	log.Printf("[WUG] Created monitor with ID: %s\n", d.Id())
+	monitorLogger.Infof("Created monitor with ID: %s", d.Id())
 
-	return resourceMonitorRead(d, m)
+	return resourceMonitorRead(ctx, d, m)
 }
 
-func resourceMonitorRead(d *schema.ResourceData, m interface{}) error {
-	resty := m.(*Client).Resty
-	token := m.(*Client).Token
-	config := m.(*Client).Config
-
-	id := d.Id()
-	var deviceId = d.Get("device_id").(string)
-
-	resp, err := resty.R().
-		SetHeader("Content-Type", "application/json").
-		SetAuthToken(token).
-		Get(config.URL + "/devices/" + deviceId + "/monitors/" + id )
+func resourceMonitorRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*wugapi.Client)
 
-	if err != nil {
-		return err
-	} else if resp.StatusCode() != 200 {
-		return errors.New(string(resp.Body()))
-	} else if resp.StatusCode() == 404 {
-		/* The monitor does not exist anymore. */
-		d.SetId("")
-		return errors.New(string(resp.Body()))
-	}
-
-	var monitor MonitorTemplate
-	err = json.Unmarshal([]byte(gjson.GetBytes(resp.Body(), "data").Raw), &monitor)
-	if err!= nil {
-		return err
+	monitor, err := client.GetDeviceMonitor(ctx, d.Get("device_id").(string), d.Id())
+	if errors.Is(err, wugapi.ErrNotFound) {
+		return goneDiag(d, "Monitor assignment")
+	} else if err != nil {
+		return errorDiag(ctx, "Unable to read monitor assignment "+d.Id(), err, nil)
 	}
 
-	d.Set("type", monitor.Type)
+	/* Depending on the version, WUG capitalizes the type. */
+	d.Set("type", strings.ToLower(monitor.Type))
 	d.Set("monitor_type_class_id", monitor.MonitorTypeClassId)
 	d.Set("monitor_type_id", monitor.MonitorTypeId)
 	d.Set("monitor_type_name", monitor.MonitorTypeName)
-	d.Set("active", monitor.Active)
-	d.Set("performance", monitor.Performance)
+
+	active := make([]map[string]interface{}, 0)
+	performance := make([]map[string]interface{}, 0)
+
+	switch strings.ToLower(monitor.Type) {
+	case "active":
+		active = append(active, map[string]interface{}{
+			"critical_order":           monitor.Active.CriticalOrder,
+			"action_policy_name":       monitor.Active.ActionPolicyName,
+			"action_policy_id":         monitor.Active.ActionPolicyId,
+			"comment":                  monitor.Active.Comment,
+			"argument":                 monitor.Active.Argument,
+			"polling_interval_seconds": monitor.Active.PollingIntervalSeconds,
+			"interface_id":             monitor.Active.InterfaceId,
+		})
+	case "performance":
+		performance = append(performance, map[string]interface{}{
+			"polling_interval_minutes": monitor.Performance.PollingIntervalMinutes,
+		})
+	}
+
+	d.Set("active", active)
+	d.Set("performance", performance)
 
 	return nil
 }
 
-func resourceMonitorUpdate(d *schema.ResourceData, m interface{}) error {
-	return resourceMonitorRead(d, m)
+// resourceMonitorImport splits a "<deviceId>/<assignmentId>" import ID.
+func resourceMonitorImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	parts := strings.SplitN(d.Id(), "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("unexpected import ID %q, expected <deviceId>/<assignmentId>", d.Id())
+	}
+
+	d.Set("device_id", parts[0])
+	d.SetId(parts[1])
+
+	return []*schema.ResourceData{d}, nil
 }
 
-func resourceMonitorDelete(d *schema.ResourceData, m interface{}) error {
-	resty := m.(*Client).Resty
-	token := m.(*Client).Token
-	config := m.(*Client).Config
+func resourceMonitorUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*wugapi.Client)
+
+	/* Only the parameters blocks can change, anything else is ForceNew. */
+	if d.HasChanges("active", "performance") {
+		err := client.UpdateDeviceMonitor(ctx, d.Get("device_id").(string), d.Id(), buildMonitorTemplate(d))
+		if err != nil {
+			/* A parameter WUG rejects is the fault of the block changed. */
+			path := cty.GetAttrPath("performance")
+			if d.HasChange("active") {
+				path = cty.GetAttrPath("active")
+			}
+			return errorDiag(ctx, "Unable to update monitor assignment "+d.Id(), err, rejectedPath(err, path))
+		}
+
+		monitorLogger.Infof("Updated monitor with ID: %s", d.Id())
+	}
 
-	id := d.Id()
-	var deviceId = d.Get("device_id").(string)
+	return resourceMonitorRead(ctx, d, m)
+}
 
-	resp, err := resty.R().
-		SetHeader("Content-Type", "application/json").
-		SetAuthToken(token).
-		Delete(config.URL + "/devices/" + deviceId + "/monitors/" + id)
+func resourceMonitorDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*wugapi.Client)
 
-	if err != nil {
-		return err
-	} else if resp.StatusCode() != 200 {
-		return errors.New(string(resp.Body()))
+	if err := client.RemoveDeviceMonitor(ctx, d.Get("device_id").(string), d.Id()); err != nil {
+		return errorDiag(ctx, "Unable to remove monitor assignment "+d.Id(), err, nil)
 	}
 
 	d.SetId("")
@@ -0,0 +1,182 @@
+package wug
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+
+	"github.com/nerimcloud/terraform-provider-wug/wugapi/wugtest"
+)
+
+func TestAccMonitor_basic(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDeviceDestroy(srv),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccMonitorConfig(srv, "mon ping"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckMonitorExists(srv, "wug_monitor.test"),
+					resource.TestCheckResourceAttr("wug_monitor.test", "type", "active"),
+					resource.TestCheckResourceAttr("wug_monitor.test", "monitor_type_name", "Ping"),
+					resource.TestCheckResourceAttr("wug_monitor.test", "active.0.comment", "mon ping"),
+					resource.TestCheckResourceAttr("wug_monitor.test", "active.0.polling_interval_seconds", "60"),
+				),
+			},
+			{
+				ResourceName:      "wug_monitor.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateIdFunc: testAccMonitorImportID("wug_monitor.test"),
+			},
+		},
+	})
+}
+
+func TestAccMonitor_update(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	var id string
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDeviceDestroy(srv),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccMonitorConfig(srv, "mon ping"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckMonitorExists(srv, "wug_monitor.test"),
+					testAccCheckDeviceID("wug_monitor.test", &id),
+				),
+			},
+			{
+				Config: testAccMonitorConfig(srv, "mon ping updated"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDeviceID("wug_monitor.test", &id),
+					resource.TestCheckResourceAttr("wug_monitor.test", "active.0.comment", "mon ping updated"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccMonitor_typeCase(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDeviceDestroy(srv),
+		Steps: []resource.TestStep{
+			{
+				/* The plan that follows must stay empty. */
+				Config: testAccMonitorConfig(srv, "mon ping"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckMonitorExists(srv, "wug_monitor.test"),
+					testAccSetMonitorType(srv, "wug_monitor.test", "Active"),
+				),
+			},
+			{
+				Config: testAccMonitorConfig(srv, "mon ping"),
+				Check:  resource.TestCheckResourceAttr("wug_monitor.test", "type", "active"),
+			},
+		},
+	})
+}
+
+func TestAccMonitor_disappears(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDeviceDestroy(srv),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccMonitorConfig(srv, "mon ping"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckMonitorExists(srv, "wug_monitor.test"),
+					testAccDeleteMonitor(srv, "wug_monitor.test"),
+				),
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func testAccCheckMonitorExists(srv *wugtest.Server, name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("resource not found: %s", name)
+		}
+
+		device, ok := srv.Device(rs.Primary.Attributes["device_id"])
+		if !ok {
+			return fmt.Errorf("device %s does not exist in WUG", rs.Primary.Attributes["device_id"])
+		}
+
+		if _, ok := device.Monitors[rs.Primary.ID]; !ok {
+			return fmt.Errorf("monitor assignment %s does not exist in WUG", rs.Primary.ID)
+		}
+
+		return nil
+	}
+}
+
+func testAccDeleteMonitor(srv *wugtest.Server, name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs := s.RootModule().Resources[name]
+		srv.DeleteDeviceMonitor(rs.Primary.Attributes["device_id"], rs.Primary.ID)
+		return nil
+	}
+}
+
+func testAccSetMonitorType(srv *wugtest.Server, name, monitorType string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs := s.RootModule().Resources[name]
+		srv.SetDeviceMonitor(rs.Primary.Attributes["device_id"], rs.Primary.ID, "type", monitorType)
+		return nil
+	}
+}
+
+func testAccMonitorImportID(name string) resource.ImportStateIdFunc {
+	return func(s *terraform.State) (string, error) {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return "", fmt.Errorf("resource not found: %s", name)
+		}
+
+		return rs.Primary.Attributes["device_id"] + "/" + rs.Primary.ID, nil
+	}
+}
+
+func testAccMonitorConfig(srv *wugtest.Server, comment string) string {
+	return testAccDeviceConfig(srv, "web-01", "Debian") + fmt.Sprintf(`
+data "wug_monitor" "ping" {
+  type   = "active"
+  search = "Ping"
+}
+
+resource "wug_monitor" "test" {
+  device_id = wug_device.test.id
+
+  type                  = "active"
+  monitor_type_class_id = data.wug_monitor.ping.class_id
+  monitor_type_id       = data.wug_monitor.ping.id
+  monitor_type_name     = data.wug_monitor.ping.monitor_name
+
+  active {
+    action_policy_name       = "Mail Policy"
+    comment                  = %q
+    polling_interval_seconds = 60
+  }
+}
+`, comment)
+}
@@ -0,0 +1,94 @@
+package wugapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/tidwall/gjson"
+)
+
+// DeviceAttribute is WUG's internal object.
+type DeviceAttribute struct {
+	ID    string `json:"id,omitempty"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+func deviceAttributePath(deviceID, attributeID string) string {
+	return "/devices/" + url.PathEscape(deviceID) + "/attributes/" + url.PathEscape(attributeID)
+}
+
+// ListDeviceAttributes returns the custom attributes of a device.
+func (c *Client) ListDeviceAttributes(ctx context.Context, deviceID string) ([]DeviceAttribute, error) {
+	body, err := c.getAllPages(ctx, deviceAttributePath(deviceID, "-"), nil, "data")
+	if err != nil {
+		return nil, err
+	}
+
+	attributes := make([]DeviceAttribute, 0)
+	err = json.Unmarshal(body, &attributes)
+	if err != nil {
+		return nil, err
+	}
+
+	return attributes, nil
+}
+
+// AddDeviceAttribute adds a custom attribute to a device, and returns its ID.
+func (c *Client) AddDeviceAttribute(ctx context.Context, deviceID string, attribute DeviceAttribute) (string, error) {
+	body, err := c.do(ctx, resty.MethodPost, deviceAttributePath(deviceID, "-"), nil, attribute)
+	if err != nil {
+		return "", err
+	}
+
+	attributeID := gjson.GetBytes(body, "data.attributeId").String()
+
+	if len(attributeID) == 0 {
+		return "", fmt.Errorf("no attribute ID in response: %s", string(body))
+	}
+
+	return attributeID, nil
+}
+
+// GetDeviceAttribute returns a custom attribute of a device.
+func (c *Client) GetDeviceAttribute(ctx context.Context, deviceID, attributeID string) (*DeviceAttribute, error) {
+	body, err := c.do(ctx, resty.MethodGet, deviceAttributePath(deviceID, attributeID), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	data := gjson.GetBytes(body, "data")
+	if !data.Exists() || data.Type == gjson.Null {
+		return nil, fmt.Errorf("attribute %s of device %s: %w", attributeID, deviceID, ErrNotFound)
+	}
+
+	var attribute DeviceAttribute
+	err = json.Unmarshal([]byte(data.Raw), &attribute)
+	if err != nil {
+		return nil, err
+	}
+
+	return &attribute, nil
+}
+
+// UpdateDeviceAttribute changes the name or the value of a custom attribute.
+func (c *Client) UpdateDeviceAttribute(ctx context.Context, deviceID, attributeID string, attribute DeviceAttribute) error {
+	_, err := c.do(ctx, resty.MethodPut, deviceAttributePath(deviceID, attributeID), nil, attribute)
+
+	return err
+}
+
+// RemoveDeviceAttribute removes a custom attribute from a device. Removing a
+// missing attribute is not an error.
+func (c *Client) RemoveDeviceAttribute(ctx context.Context, deviceID, attributeID string) error {
+	_, err := c.do(ctx, resty.MethodDelete, deviceAttributePath(deviceID, attributeID), nil, nil)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+
+	return err
+}
@@ -0,0 +1,220 @@
+package wugapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/tidwall/gjson"
+)
+
+// tokenRefreshMargin is how long before its expiry an access token is renewed.
+const tokenRefreshMargin = 60 * time.Second
+
+// Config holds API configuration parameters.
+type Config struct {
+	InsecureFlag bool
+	User         string
+	Password     string
+	URL          string
+
+	/* TLS settings, see tlsConfig. Client certificate and key are either
+	 * file paths or inline PEM. */
+	CACertFile    string
+	CACertPEM     string
+	ClientCert    string
+	ClientKey     string
+	TLSServerName string
+
+	/* Retries on transient failures, see configureRetries. */
+	MaxRetries   int
+	RetryMaxWait time.Duration
+}
+
+// Client holds the Resty instance and API configuration.
+type Client struct {
+	Resty  *resty.Client
+	Config *Config
+
+	/* Resty instance used for /token calls, without the auth middleware. */
+	auth *resty.Client
+
+	mu           sync.Mutex
+	token        string
+	refreshToken string
+	expiry       time.Time
+}
+
+// NewClient returns an authenticated REST client for WUG.
+func NewClient(ctx context.Context, c *Config) (*Client, error) {
+	client := new(Client)
+
+	tlsConfig, err := c.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	client.Config = c
+	client.Resty = resty.New()
+	client.Resty.SetTLSClientConfig(tlsConfig)
+	client.auth = resty.NewWithClient(client.Resty.GetClient())
+
+	/* Every request gets a valid token, and is replayed once on a 401. */
+	client.Resty.OnBeforeRequest(client.setAuthToken)
+	client.configureRetries()
+
+	enableHTTPDebug(client.Resty)
+	enableHTTPDebug(client.auth)
+
+	if err := client.login(ctx); err != nil {
+		return nil, err
+	}
+
+	logger.Infof("Authenticated as %s", c.User)
+
+	return client, nil
+}
+
+// Token returns a valid access token, renewing it if it is about to expire.
+func (c *Client) Token(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token != "" && (c.expiry.IsZero() || time.Now().Before(c.expiry.Add(-tokenRefreshMargin))) {
+		return c.token, nil
+	}
+
+	if c.refreshToken != "" {
+		err := c.refresh(ctx)
+		if err == nil {
+			return c.token, nil
+		}
+
+		logger.Warnf("Token refresh failed, logging in again: %s", err)
+	}
+
+	if err := c.login(ctx); err != nil {
+		return "", err
+	}
+
+	return c.token, nil
+}
+
+// login requests a new token with the password grant.
+func (c *Client) login(ctx context.Context) error {
+	params := url.Values{}
+	params.Add("grant_type", "password")
+	params.Add("username", c.Config.User)
+	params.Add("password", c.Config.Password)
+
+	return c.requestToken(ctx, params)
+}
+
+// refresh requests a new token with the refresh_token grant.
+func (c *Client) refresh(ctx context.Context) error {
+	params := url.Values{}
+	params.Add("grant_type", "refresh_token")
+	params.Add("refresh_token", c.refreshToken)
+
+	return c.requestToken(ctx, params)
+}
+
+func (c *Client) requestToken(ctx context.Context, params url.Values) error {
+	resp, err := c.auth.R().
+		SetContext(ctx).
+		SetHeader("Content-Type", "application/json").
+		SetBody(params.Encode()).
+		Post(c.Config.URL + "/token")
+
+	if err != nil {
+		return err
+	} else if resp.StatusCode() != 200 {
+		return errors.New(string(resp.Body()))
+	}
+
+	token := gjson.GetBytes(resp.Body(), "access_token").String()
+	if len(token) == 0 {
+		return errors.New("no access token in /token response")
+	}
+
+	c.token = token
+	c.refreshToken = gjson.GetBytes(resp.Body(), "refresh_token").String()
+	c.expiry = time.Time{}
+
+	if expiresIn := gjson.GetBytes(resp.Body(), "expires_in").Int(); expiresIn > 0 {
+		c.expiry = time.Now().Add(time.Duration(expiresIn) * time.Second)
+	}
+
+	return nil
+}
+
+// invalidate forgets the given access token, unless it was already replaced.
+func (c *Client) invalidate(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token == token {
+		c.token = ""
+	}
+}
+
+func (c *Client) setAuthToken(_ *resty.Client, req *resty.Request) error {
+	token, err := c.Token(req.Context())
+	if err != nil {
+		return err
+	}
+
+	req.SetAuthToken(token)
+
+	return nil
+}
+
+func (c *Client) retryUnauthorized(resp *resty.Response, err error) bool {
+	if err != nil || resp == nil || resp.StatusCode() != http.StatusUnauthorized {
+		return false
+	}
+
+	if resp.Request.Attempt > 1 {
+		return false
+	}
+
+	logger.Infof("Got a 401 from %s, renewing the access token", resp.Request.URL)
+	c.invalidate(resp.Request.Token)
+
+	return true
+}
+
+// do sends a JSON request to the API and returns the response body. The
+// call is aborted, retries included, as soon as ctx is done.
+func (c *Client) do(ctx context.Context, method, path string, query map[string]string, body interface{}) ([]byte, error) {
+	req := c.Resty.R().
+		SetContext(ctx).
+		SetHeader("Content-Type", "application/json").
+		SetHeader("Accept", "application/json")
+
+	if query != nil {
+		req.SetQueryParams(query)
+	}
+	if body != nil {
+		req.SetBody(body)
+	}
+
+	resp, err := req.Execute(method, c.Config.URL+path)
+
+	if err != nil {
+		return nil, err
+	} else if !resp.IsSuccess() {
+		return nil, &Error{
+			StatusCode: resp.StatusCode(),
+			Method:     method,
+			URL:        path,
+			Body:       string(resp.Body()),
+		}
+	}
+
+	return resp.Body(), nil
+}
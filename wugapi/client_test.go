@@ -0,0 +1,423 @@
+package wugapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/nerimcloud/terraform-provider-wug/wugapi"
+	"github.com/nerimcloud/terraform-provider-wug/wugapi/wugtest"
+)
+
+func newTestClient(t *testing.T, srv *wugtest.Server) *wugapi.Client {
+	t.Helper()
+
+	client, err := wugapi.NewClient(context.Background(), &wugapi.Config{
+		URL:          srv.URL(),
+		User:         wugtest.User,
+		Password:     wugtest.Password,
+		MaxRetries:   2,
+		RetryMaxWait: time.Second,
+	})
+	if err != nil {
+		t.Fatalf("NewClient: %s", err)
+	}
+
+	return client
+}
+
+func TestClientBadCredentials(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	_, err := wugapi.NewClient(context.Background(), &wugapi.Config{
+		URL:      srv.URL(),
+		User:     wugtest.User,
+		Password: "wrong",
+	})
+	if err == nil {
+		t.Fatal("expected an authentication error")
+	}
+}
+
+func TestClientRenewsTokenOn401(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	ctx := context.Background()
+	client := newTestClient(t, srv)
+
+	srv.ExpireTokens()
+
+	if _, err := client.SearchMonitors(ctx, "active", "Ping"); err != nil {
+		t.Fatalf("SearchMonitors after token expiry: %s", err)
+	}
+
+	if got := srv.Requests(http.MethodGet, "/monitors/-"); got != 2 {
+		t.Errorf("expected the search to be replayed once, got %d calls", got)
+	}
+	if got := srv.Requests(http.MethodPost, "/token"); got != 2 {
+		t.Errorf("expected one token renewal, got %d token calls", got)
+	}
+}
+
+func TestClientRefreshesTokenBeforeExpiry(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	/* Shorter than the refresh margin: every call renews the token. */
+	srv.TokenLifetime = 30 * time.Second
+
+	ctx := context.Background()
+	client := newTestClient(t, srv)
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.SearchMonitors(ctx, "active", "Ping"); err != nil {
+			t.Fatalf("SearchMonitors: %s", err)
+		}
+	}
+
+	if got := srv.Requests(http.MethodPost, "/token"); got != 3 {
+		t.Errorf("expected a login and two refreshes, got %d token calls", got)
+	}
+	if got := srv.Requests(http.MethodGet, "/monitors/-"); got != 2 {
+		t.Errorf("expected no 401 replay, got %d calls", got)
+	}
+}
+
+func TestClientRetries(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	ctx := context.Background()
+	client := newTestClient(t, srv)
+
+	srv.Fail(http.MethodGet, "/devices/1/config/template", http.StatusServiceUnavailable, "")
+	srv.Fail(http.MethodPatch, "/devices/-/config/template", http.StatusBadGateway, "")
+
+	if _, err := client.GetDeviceTemplate(ctx, "1"); err == nil {
+		t.Error("expected GetDeviceTemplate to fail")
+	}
+	if got := srv.Requests(http.MethodGet, "/devices/1/config/template"); got != 3 {
+		t.Errorf("expected 3 attempts on a 503, got %d", got)
+	}
+
+	/* A 502 may hide a processed PATCH, it must not be replayed. */
+	if _, err := client.ApplyDeviceTemplates(ctx, []string{"basic"}, []wugapi.DeviceTemplate{{Name: "dev"}}); err == nil {
+		t.Error("expected ApplyDeviceTemplates to fail")
+	}
+	if got := srv.Requests(http.MethodPatch, "/devices/-/config/template"); got != 1 {
+		t.Errorf("expected a single PATCH attempt on a 502, got %d", got)
+	}
+}
+
+func TestDeviceLifecycle(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	ctx := context.Background()
+	client := newTestClient(t, srv)
+
+	idMap, err := client.ApplyDeviceTemplates(ctx, []string{"basic"}, []wugapi.DeviceTemplate{{
+		Name:       "web-01",
+		Interfaces: []wugapi.DeviceTemplateInterface{{IsDefault: true, NetworkAddress: "10.0.0.1", NetworkName: "web-01"}},
+		Groups:     []wugapi.DeviceTemplateReferenceName{{Name: "Linux", Parents: []string{"ROOT"}}},
+		Os:         "Debian",
+	}})
+	if err != nil {
+		t.Fatalf("ApplyDeviceTemplates: %s", err)
+	}
+	if len(idMap) != 1 || idMap[0].ResultID == "" {
+		t.Fatalf("unexpected idMap: %#v", idMap)
+	}
+
+	id := idMap[0].ResultID
+
+	template, err := client.GetDeviceTemplate(ctx, id)
+	if err != nil {
+		t.Fatalf("GetDeviceTemplate: %s", err)
+	}
+	if template.Name != "web-01" || template.Os != "Debian" || len(template.Interfaces) != 1 {
+		t.Errorf("unexpected template: %#v", template)
+	}
+
+	if err := client.DeleteDevice(ctx, id); err != nil {
+		t.Fatalf("DeleteDevice: %s", err)
+	}
+	if _, err := client.GetDeviceTemplate(ctx, id); !errors.Is(err, wugapi.ErrNotFound) {
+		t.Errorf("expected ErrNotFound after deletion, got %v", err)
+	}
+	if err := client.DeleteDevice(ctx, id); err != nil {
+		t.Errorf("deleting a missing device should succeed, got %s", err)
+	}
+}
+
+func TestDeviceMonitorLifecycle(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	ctx := context.Background()
+	client := newTestClient(t, srv)
+
+	deviceID := srv.AddDevice(wugtest.Object{"displayName": "web-01"})
+
+	monitors, err := client.SearchMonitors(ctx, "active", "Ping")
+	if err != nil {
+		t.Fatalf("SearchMonitors: %s", err)
+	}
+	if len(monitors) != 1 || monitors[0].MonitorTypeInfo.ClassId == "" {
+		t.Fatalf("unexpected search result: %#v", monitors)
+	}
+
+	assignmentID, err := client.AddDeviceMonitor(ctx, deviceID, wugapi.MonitorTemplate{
+		Type:               "active",
+		MonitorTypeClassId: monitors[0].MonitorTypeInfo.ClassId,
+		MonitorTypeId:      monitors[0].MonitorId,
+		MonitorTypeName:    monitors[0].Name,
+		Active:             wugapi.MonitorActiveParameters{Comment: "ping", PollingIntervalSeconds: 60},
+	})
+	if err != nil {
+		t.Fatalf("AddDeviceMonitor: %s", err)
+	}
+
+	monitor, err := client.GetDeviceMonitor(ctx, deviceID, assignmentID)
+	if err != nil {
+		t.Fatalf("GetDeviceMonitor: %s", err)
+	}
+	if monitor.Active.Comment != "ping" || monitor.Active.PollingIntervalSeconds != 60 {
+		t.Errorf("unexpected monitor: %#v", monitor)
+	}
+
+	monitor.Active.Comment = "ping updated"
+	if err := client.UpdateDeviceMonitor(ctx, deviceID, assignmentID, *monitor); err != nil {
+		t.Fatalf("UpdateDeviceMonitor: %s", err)
+	}
+	if monitor, err = client.GetDeviceMonitor(ctx, deviceID, assignmentID); err != nil || monitor.Active.Comment != "ping updated" {
+		t.Errorf("expected the comment to be updated, got %#v (%v)", monitor, err)
+	}
+
+	srv.DeleteDeviceMonitor(deviceID, assignmentID)
+
+	if _, err := client.GetDeviceMonitor(ctx, deviceID, assignmentID); !errors.Is(err, wugapi.ErrNotFound) {
+		t.Errorf("expected ErrNotFound after deletion, got %v", err)
+	}
+	if err := client.RemoveDeviceMonitor(ctx, deviceID, assignmentID); err != nil {
+		t.Errorf("removing a missing assignment should succeed, got %s", err)
+	}
+}
+
+func TestDeviceGroupLifecycle(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	/* One group per page, the listing must follow nextPageId. */
+	srv.PageSize = 1
+
+	ctx := context.Background()
+	client := newTestClient(t, srv)
+
+	parentID, err := client.CreateDeviceGroup(ctx, wugtest.RootGroupID, wugapi.DeviceGroup{Name: "Datacenter"})
+	if err != nil {
+		t.Fatalf("CreateDeviceGroup: %s", err)
+	}
+	groupID, err := client.CreateDeviceGroup(ctx, parentID, wugapi.DeviceGroup{Name: "Linux", Description: "Linux servers"})
+	if err != nil {
+		t.Fatalf("CreateDeviceGroup: %s", err)
+	}
+
+	tree, err := client.GetDeviceGroupTree(ctx)
+	if err != nil {
+		t.Fatalf("GetDeviceGroupTree: %s", err)
+	}
+	if len(tree) != 3 {
+		t.Errorf("expected 3 groups over 3 pages, got %d", len(tree))
+	}
+	if got := tree.Path(groupID); len(got) != 3 || got[0] != wugtest.RootGroupName || got[2] != "Linux" {
+		t.Errorf("unexpected path: %q", got)
+	}
+	if group, err := tree.Find([]string{wugtest.RootGroupName, "Datacenter", "Linux"}); err != nil || group.ID != groupID {
+		t.Errorf("expected to find group %s, got %#v (%v)", groupID, group, err)
+	}
+	if _, err := tree.Find([]string{wugtest.RootGroupName, "Linux"}); !errors.Is(err, wugapi.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a wrong path, got %v", err)
+	}
+
+	deviceID := srv.AddDevice(wugtest.Object{"displayName": "web-01"})
+	if err := client.UpdateDeviceGroupMembers(ctx, groupID, []string{deviceID}, nil); err != nil {
+		t.Fatalf("UpdateDeviceGroupMembers: %s", err)
+	}
+	if devices, err := client.ListDeviceGroupDevices(ctx, groupID); err != nil || len(devices) != 1 || devices[0].Name != "web-01" {
+		t.Errorf("expected web-01 in the group, got %#v (%v)", devices, err)
+	}
+	if err := client.UpdateDeviceGroupMembers(ctx, groupID, nil, []string{deviceID}); err != nil {
+		t.Fatalf("UpdateDeviceGroupMembers: %s", err)
+	}
+	if devices, err := client.ListDeviceGroupDevices(ctx, groupID); err != nil || len(devices) != 0 {
+		t.Errorf("expected an empty group, got %#v (%v)", devices, err)
+	}
+
+	err = client.UpdateDeviceGroup(ctx, groupID, wugapi.DeviceGroup{ParentGroupID: wugtest.RootGroupID, Name: "Debian"})
+	if err != nil {
+		t.Fatalf("UpdateDeviceGroup: %s", err)
+	}
+	group, err := client.GetDeviceGroup(ctx, groupID)
+	if err != nil {
+		t.Fatalf("GetDeviceGroup: %s", err)
+	}
+	if group.Name != "Debian" || group.Description != "" || group.ParentGroupID != wugtest.RootGroupID {
+		t.Errorf("unexpected group after update: %#v", group)
+	}
+
+	if err := client.DeleteDeviceGroup(ctx, groupID); err != nil {
+		t.Fatalf("DeleteDeviceGroup: %s", err)
+	}
+	if _, err := client.GetDeviceGroup(ctx, groupID); !errors.Is(err, wugapi.ErrNotFound) {
+		t.Errorf("expected ErrNotFound after deletion, got %v", err)
+	}
+	if err := client.DeleteDeviceGroup(ctx, groupID); err != nil {
+		t.Errorf("deleting a missing group should succeed, got %s", err)
+	}
+}
+
+func TestDynamicDeviceGroupRename(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	ctx := context.Background()
+	client := newTestClient(t, srv)
+
+	filter := "role = 'Windows Server'"
+	groupID, err := client.CreateDeviceGroup(ctx, wugtest.RootGroupID, wugapi.DeviceGroup{
+		Name:      "Windows servers",
+		GroupType: wugapi.DynamicDeviceGroup,
+		Filter:    filter,
+	})
+	if err != nil {
+		t.Fatalf("CreateDeviceGroup: %s", err)
+	}
+
+	err = client.UpdateDeviceGroup(ctx, groupID, wugapi.DeviceGroup{
+		ParentGroupID: wugtest.RootGroupID,
+		Name:          "Windows hosts",
+		GroupType:     wugapi.DynamicDeviceGroup,
+		Filter:        filter,
+	})
+	if err != nil {
+		t.Fatalf("UpdateDeviceGroup: %s", err)
+	}
+
+	group, err := client.GetDeviceGroup(ctx, groupID)
+	if err != nil {
+		t.Fatalf("GetDeviceGroup: %s", err)
+	}
+	if group.Name != "Windows hosts" || group.Filter != filter {
+		t.Errorf("expected the renamed group to keep its filter, got %#v", group)
+	}
+}
+
+func TestDeviceCredentialLifecycle(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	srv.PageSize = 1
+
+	ctx := context.Background()
+	client := newTestClient(t, srv)
+
+	library, err := client.ListCredentials(ctx)
+	if err != nil {
+		t.Fatalf("ListCredentials: %s", err)
+	}
+	if len(library) != 3 {
+		t.Errorf("expected 3 credentials over 3 pages, got %d", len(library))
+	}
+	v2, err := wugapi.FindCredential(library, "snmp", "public-v2")
+	if err != nil {
+		t.Fatalf("FindCredential: %s", err)
+	}
+	if _, err := wugapi.FindCredential(library, "SNMP", "missing"); !errors.Is(err, wugapi.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a missing credential, got %v", err)
+	}
+
+	deviceID := srv.AddDevice(wugtest.Object{
+		"displayName": "web-01",
+		"credentials": []interface{}{wugtest.Object{"credentialType": "Windows", "credential": "domain-admin"}},
+	})
+
+	if err := client.AssignDeviceCredential(ctx, deviceID, v2.ID); err != nil {
+		t.Fatalf("AssignDeviceCredential: %s", err)
+	}
+	assigned, err := client.ListDeviceCredentials(ctx, deviceID)
+	if err != nil {
+		t.Fatalf("ListDeviceCredentials: %s", err)
+	}
+	if len(assigned) != 2 {
+		t.Errorf("expected 2 assigned credentials, got %#v", assigned)
+	}
+
+	for _, credential := range assigned {
+		if err := client.RemoveDeviceCredential(ctx, deviceID, credential.ID); err != nil {
+			t.Fatalf("RemoveDeviceCredential: %s", err)
+		}
+	}
+	if got := srv.DeviceCredentials(deviceID); len(got) != 0 {
+		t.Errorf("expected no credential left, got %v", got)
+	}
+	if err := client.RemoveDeviceCredential(ctx, deviceID, v2.ID); err != nil {
+		t.Errorf("removing a credential which is not assigned should succeed, got %s", err)
+	}
+}
+
+func TestApplyDeviceTemplatesPartialFailure(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	ctx := context.Background()
+	client := newTestClient(t, srv)
+
+	idMap, err := client.ApplyDeviceTemplates(ctx, []string{"basic"}, []wugapi.DeviceTemplate{
+		{TemplateID: "web-01", Name: "web-01"},
+		{TemplateID: "web-02", Name: "web-02", Credentials: []wugapi.DeviceTemplateCredentials{{CredentialType: "SNMP", Name: "missing"}}},
+	})
+
+	var failures wugapi.DeviceTemplateErrors
+	if !errors.As(err, &failures) || len(failures) != 1 || failures[0].TemplateID != "web-02" {
+		t.Fatalf("expected web-02 to fail, got %v", err)
+	}
+	if len(idMap) != 1 || idMap[0].TemplateID != "web-01" {
+		t.Fatalf("expected web-01 to be created, got %#v", idMap)
+	}
+	if _, ok := srv.Device(idMap[0].ResultID); !ok {
+		t.Errorf("device %s does not exist", idMap[0].ResultID)
+	}
+}
+
+func TestDeviceTemplateMarshalJSON(t *testing.T) {
+	template := wugapi.DeviceTemplate{
+		Name: "web-01",
+		Interfaces: []wugapi.DeviceTemplateInterface{
+			{NetworkAddress: "10.0.0.1", NetworkName: "web-01"},
+		},
+		Extra: map[string]interface{}{
+			"displayName": "exported",
+			"notes":       "Imported from the console",
+			"interfaces": []interface{}{
+				map[string]interface{}{"networkAddress": "10.0.0.1", "defaultInterface": true, "timeout": 5},
+				map[string]interface{}{"networkAddress": "10.0.0.2"},
+			},
+		},
+	}
+
+	data, err := json.Marshal(template)
+	if err != nil {
+		t.Fatalf("Marshal: %s", err)
+	}
+
+	want := `{"displayName":"web-01","interfaces":[{"defaultInterface":false,"networkAddress":"10.0.0.1","networkName":"web-01","pollUsingNetworkName":false,"timeout":5}],"notes":"Imported from the console"}`
+	if string(data) != want {
+		t.Errorf("expected %s, got %s", want, data)
+	}
+}
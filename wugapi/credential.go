@@ -0,0 +1,86 @@
+package wugapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// Credential is WUG's internal object, as in the credential library.
+type Credential struct {
+	ID          string `json:"id,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Type        string `json:"type,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+func deviceCredentialPath(deviceID, credentialID string) string {
+	return "/devices/" + url.PathEscape(deviceID) + "/credentials/" + url.PathEscape(credentialID)
+}
+
+func decodeCredentials(body []byte) ([]Credential, error) {
+	credentials := make([]Credential, 0)
+	err := json.Unmarshal(body, &credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	return credentials, nil
+}
+
+// ListCredentials returns the credentials of the library.
+func (c *Client) ListCredentials(ctx context.Context) ([]Credential, error) {
+	body, err := c.getAllPages(ctx, "/credentials/-", nil, "data.credentials")
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeCredentials(body)
+}
+
+// FindCredential looks a credential of the library up by type and name. The
+// type is matched case-insensitively, as WUG does.
+func FindCredential(credentials []Credential, credentialType, name string) (*Credential, error) {
+	for i, credential := range credentials {
+		if strings.EqualFold(credential.Type, credentialType) && credential.Name == name {
+			return &credentials[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("credential %s of type %s: %w", name, credentialType, ErrNotFound)
+}
+
+// ListDeviceCredentials returns the credentials assigned to a device.
+func (c *Client) ListDeviceCredentials(ctx context.Context, deviceID string) ([]Credential, error) {
+	body, err := c.getAllPages(ctx, deviceCredentialPath(deviceID, "-"), nil, "data.credentials")
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeCredentials(body)
+}
+
+// AssignDeviceCredential assigns a credential of the library to a device. It
+// replaces the credential of the same type, a device having at most one
+// credential per type.
+func (c *Client) AssignDeviceCredential(ctx context.Context, deviceID, credentialID string) error {
+	_, err := c.do(ctx, resty.MethodPut, deviceCredentialPath(deviceID, credentialID), nil, nil)
+
+	return err
+}
+
+// RemoveDeviceCredential removes a credential from a device. Removing a
+// credential which is not assigned is not an error.
+func (c *Client) RemoveDeviceCredential(ctx context.Context, deviceID, credentialID string) error {
+	_, err := c.do(ctx, resty.MethodDelete, deviceCredentialPath(deviceID, credentialID), nil, nil)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+
+	return err
+}
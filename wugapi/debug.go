@@ -0,0 +1,94 @@
+package wugapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+
+	"github.com/nerimcloud/terraform-provider-wug/logging"
+)
+
+// DebugHTTPEnv enables redacted request/response dumps when set.
+const DebugHTTPEnv = "WUG_DEBUG_HTTP"
+
+var logger = logging.New("client")
+
+// enableHTTPDebug dumps every exchange of the given Resty instance when
+// DebugHTTPEnv is set.
+func enableHTTPDebug(r *resty.Client) {
+	if os.Getenv(DebugHTTPEnv) == "" {
+		return
+	}
+
+	r.OnAfterResponse(func(_ *resty.Client, resp *resty.Response) error {
+		logger.Debugf("HTTP exchange:\n%s\n%s", dumpRequest(resp.Request), dumpResponse(resp))
+		return nil
+	})
+
+	r.OnError(func(req *resty.Request, err error) {
+		logger.Debugf("HTTP exchange failed: %s\n%s", err, dumpRequest(req))
+	})
+}
+
+func dumpHeaders(b *strings.Builder, header http.Header) {
+	keys := make([]string, 0, len(header))
+	for key := range header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := strings.Join(header[key], ", ")
+		if strings.EqualFold(key, "Authorization") {
+			value = logging.Redacted
+		}
+		fmt.Fprintf(b, "%s: %s\n", key, value)
+	}
+}
+
+func dumpRequest(req *resty.Request) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "---[ REQUEST ]---\n%s %s\n", req.Method, req.URL)
+
+	if req.RawRequest != nil {
+		dumpHeaders(&b, req.RawRequest.Header)
+	} else {
+		dumpHeaders(&b, req.Header)
+	}
+
+	var body []byte
+	switch v := req.Body.(type) {
+	case nil:
+	case string:
+		body = []byte(v)
+	case []byte:
+		body = v
+	default:
+		body, _ = json.Marshal(v)
+	}
+
+	if len(body) > 0 {
+		fmt.Fprintf(&b, "\n%s\n", logging.Redact(body))
+	}
+
+	return b.String()
+}
+
+func dumpResponse(resp *resty.Response) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "---[ RESPONSE ]---\n%s (%s)\n", resp.Status(), resp.Time())
+	dumpHeaders(&b, resp.Header())
+
+	if len(resp.Body()) > 0 {
+		fmt.Fprintf(&b, "\n%s\n", logging.Redact(resp.Body()))
+	}
+
+	return b.String()
+}
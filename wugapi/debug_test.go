@@ -0,0 +1,67 @@
+package wugapi
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/nerimcloud/terraform-provider-wug/logging"
+	"github.com/nerimcloud/terraform-provider-wug/wugapi/wugtest"
+)
+
+func TestHTTPDebugRedactsSecrets(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	os.Setenv(DebugHTTPEnv, "1")
+	defer os.Unsetenv(DebugHTTPEnv)
+
+	var output bytes.Buffer
+	log.SetOutput(&output)
+	defer log.SetOutput(os.Stderr)
+
+	ctx := context.Background()
+
+	/* Log in with the password, call the API with the bearer token, then
+	 * refresh it. */
+	client, err := NewClient(ctx, &Config{URL: srv.URL(), User: wugtest.User, Password: wugtest.Password})
+	if err != nil {
+		t.Fatalf("NewClient: %s", err)
+	}
+	secrets := []string{"password=" + wugtest.Password, client.token, client.refreshToken}
+
+	deviceID := srv.AddDevice(wugtest.Object{
+		"displayName": "web-01",
+		"credentials": []interface{}{wugtest.Object{"credentialType": "SNMP", "credential": "public-v2"}},
+	})
+	if _, err := client.GetDeviceTemplate(ctx, deviceID); err != nil {
+		t.Fatalf("GetDeviceTemplate: %s", err)
+	}
+
+	if err := client.refresh(ctx); err != nil {
+		t.Fatalf("refresh: %s", err)
+	}
+	secrets = append(secrets, client.token, client.refreshToken)
+
+	dump := output.String()
+	for _, want := range []string{
+		"password=" + logging.Redacted,
+		"refresh_token=" + logging.Redacted,
+		"Authorization: " + logging.Redacted,
+		`"access_token":"` + logging.Redacted + `"`,
+		`"credential":"public-v2","credentialType":"SNMP"`,
+	} {
+		if !strings.Contains(dump, want) {
+			t.Errorf("expected the dump to contain %s", want)
+		}
+	}
+
+	for _, secret := range secrets {
+		if strings.Contains(dump, secret) {
+			t.Errorf("the dump leaks %s:\n%s", secret, dump)
+		}
+	}
+}
@@ -0,0 +1,291 @@
+package wugapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/tidwall/gjson"
+)
+
+// DeviceTemplateInterface is WUG's internal object.
+type DeviceTemplateInterface struct {
+	IsDefault            bool   `json:"defaultInterface,omitempty"`
+	PollUsingNetworkName bool   `json:"pollUsingNetworkName,omitempty"`
+	NetworkAddress       string `json:"networkAddress,omitempty"`
+	NetworkName          string `json:"networkName,omitempty"`
+}
+
+// DeviceTemplateReferenceName is WUG's internal object.
+type DeviceTemplateReferenceName struct {
+	Name    string   `json:"name,omitempty"`
+	Parents []string `json:"parents,omitempty"`
+}
+
+// DeviceTemplateCredentials is WUG's internal object.
+type DeviceTemplateCredentials struct {
+	CredentialType string `json:"credentialType,omitempty"`
+	Name           string `json:"credential,omitempty"`
+}
+
+// DeviceTemplateActiveMonitor is WUG's internal object.
+type DeviceTemplateActiveMonitor struct {
+	Name         string `json:"name,omitempty"`
+	Argument     string `json:"argument,omitempty"`
+	Comment      string `json:"comment,omitempty"`
+	IsCritical   string `json:"isCritical,omitempty"`
+	PollingOrder int    `json:"pollingOrder,string,omitempty"`
+}
+
+// DeviceTemplatePerformanceMonitor is WUG's internal object.
+type DeviceTemplatePerformanceMonitor struct {
+	Name string `json:"name,omitempty"`
+}
+
+// DeviceTemplate is WUG's internal object.
+type DeviceTemplate struct {
+	TemplateID          string                             `json:"templateId,omitempty"`
+	Name                string                             `json:"displayName,omitempty"`
+	Interfaces          []DeviceTemplateInterface          `json:"interfaces,omitempty"`
+	Groups              []DeviceTemplateReferenceName      `json:"groups,omitempty"`
+	Credentials         []DeviceTemplateCredentials        `json:"credentials,omitempty"`
+	ActiveMonitors      []DeviceTemplateActiveMonitor      `json:"activeMonitors,omitempty"`
+	PerformanceMonitors []DeviceTemplatePerformanceMonitor `json:"performanceMonitors,omitempty"`
+	DeviceType          string                             `json:"deviceType,omitempty"`
+	SnmpOid             string                             `json:"snmpOid,omitempty"`
+	PrimaryRole         string                             `json:"primaryRole,omitempty"`
+	SubRoles            []string                           `json:"subRoles,omitempty"`
+	Os                  string                             `json:"os,omitempty"`
+	Brand               string                             `json:"brand,omitempty"`
+	ActionPolicy        string                             `json:"actionPolicy,omitempty"`
+
+	// Extra holds template fields not modelled above, as exported from the
+	// WUG console. The fields above take precedence when set.
+	Extra map[string]interface{} `json:"-"`
+}
+
+// MarshalJSON merges the typed fields of the template over its Extra fields.
+// Interfaces are merged one by one, matched by network address, so that
+// their extra properties are kept.
+func (t DeviceTemplate) MarshalJSON() ([]byte, error) {
+	/* Without the methods, to marshal the typed fields as usual. */
+	type typedTemplate DeviceTemplate
+
+	typed, err := json.Marshal(typedTemplate(t))
+	if err != nil || len(t.Extra) == 0 {
+		return typed, err
+	}
+
+	fields := make(map[string]interface{})
+	if err := json.Unmarshal(typed, &fields); err != nil {
+		return nil, err
+	}
+
+	merged := make(map[string]interface{}, len(t.Extra)+len(fields))
+	for key, value := range t.Extra {
+		merged[key] = value
+	}
+	for key, value := range fields {
+		merged[key] = value
+	}
+
+	if extra, ok := t.Extra["interfaces"].([]interface{}); ok && len(t.Interfaces) > 0 {
+		merged["interfaces"] = mergeInterfaces(extra, fields["interfaces"].([]interface{}))
+	}
+
+	return json.Marshal(merged)
+}
+
+// mergeInterfaces merges each typed interface over the extra interface with
+// the same network address.
+func mergeInterfaces(extra, typed []interface{}) []interface{} {
+	byAddress := make(map[interface{}]map[string]interface{})
+	for _, item := range extra {
+		if iface, ok := item.(map[string]interface{}); ok {
+			byAddress[iface["networkAddress"]] = iface
+		}
+	}
+
+	merged := make([]interface{}, 0, len(typed))
+	for _, item := range typed {
+		iface := item.(map[string]interface{})
+
+		combined := make(map[string]interface{})
+		for key, value := range byAddress[iface["networkAddress"]] {
+			combined[key] = value
+		}
+		for key, value := range iface {
+			combined[key] = value
+		}
+
+		/* The typed flags are omitted when false, they still win. */
+		combined["defaultInterface"] = iface["defaultInterface"] == true
+		combined["pollUsingNetworkName"] = iface["pollUsingNetworkName"] == true
+
+		merged = append(merged, combined)
+	}
+
+	return merged
+}
+
+// DeviceProperties are the attributes of a device that can be changed
+// without re-creating it. Empty values clear the matching property.
+type DeviceProperties struct {
+	Name         string   `json:"displayName"`
+	DeviceType   string   `json:"deviceType"`
+	SnmpOid      string   `json:"snmpOid"`
+	PrimaryRole  string   `json:"primaryRole"`
+	SubRoles     []string `json:"subRoles"`
+	Os           string   `json:"os"`
+	Brand        string   `json:"brand"`
+	ActionPolicy string   `json:"actionPolicy"`
+}
+
+// DeviceTemplateIDMap links a submitted template to the device it produced.
+type DeviceTemplateIDMap struct {
+	TemplateID string `json:"templateId,omitempty"`
+	ResultID   string `json:"resultId,omitempty"`
+}
+
+// DeviceTemplateError reports why a submitted template produced no device.
+type DeviceTemplateError struct {
+	TemplateID string   `json:"templateId"`
+	Messages   []string `json:"messages"`
+}
+
+// DeviceTemplateErrors is returned by ApplyDeviceTemplates when some
+// templates failed, along with the mapping of the other ones.
+type DeviceTemplateErrors []DeviceTemplateError
+
+func (e DeviceTemplateErrors) Error() string {
+	failures := make([]string, 0, len(e))
+	for _, failure := range e {
+		failures = append(failures, fmt.Sprintf("template %s: %s", failure.TemplateID, strings.Join(failure.Messages, ", ")))
+	}
+
+	return strings.Join(failures, "; ")
+}
+
+// GetDeviceTemplateJSON returns the template of a device as WUG sends it,
+// including the fields DeviceTemplate does not model.
+func (c *Client) GetDeviceTemplateJSON(ctx context.Context, deviceID string) ([]byte, error) {
+	body, err := c.do(ctx, resty.MethodGet, "/devices/"+url.PathEscape(deviceID)+"/config/template", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	deviceCount := gjson.GetBytes(body, "data.deviceCount").Int()
+
+	/* WUG may answer 200 with no device for a deleted ID. */
+	if deviceCount == 0 {
+		return nil, fmt.Errorf("device %s: %w", deviceID, ErrNotFound)
+	} else if deviceCount != 1 {
+		return nil, fmt.Errorf("Found invalid device count for %s: %d", deviceID, deviceCount)
+	}
+
+	return []byte(gjson.GetBytes(body, "data.templates.0").Raw), nil
+}
+
+// GetDeviceTemplate returns the template describing an existing device.
+func (c *Client) GetDeviceTemplate(ctx context.Context, deviceID string) (*DeviceTemplate, error) {
+	raw, err := c.GetDeviceTemplateJSON(ctx, deviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	var template DeviceTemplate
+	err = json.Unmarshal(raw, &template)
+	if err != nil {
+		return nil, err
+	}
+
+	return &template, nil
+}
+
+// ApplyDeviceTemplates creates devices from templates in a single call, and
+// returns the template to device ID mapping. Templates are identified by
+// their TemplateID, or their index when unset. When some templates fail, the
+// mapping of the other ones is returned with a DeviceTemplateErrors.
+func (c *Client) ApplyDeviceTemplates(ctx context.Context, options []string, templates []DeviceTemplate) ([]DeviceTemplateIDMap, error) {
+	params := map[string]interface{}{
+		"options":   options,
+		"templates": templates,
+	}
+
+	body, err := c.do(ctx, resty.MethodPatch, "/devices/-/config/template", nil, params)
+	if err != nil {
+		return nil, err
+	}
+
+	/* IDs may come back as numbers or strings, let gjson stringify them. */
+	idMap := make([]DeviceTemplateIDMap, 0)
+	gjson.GetBytes(body, "data.idMap").ForEach(func(_, entry gjson.Result) bool {
+		idMap = append(idMap, DeviceTemplateIDMap{
+			TemplateID: entry.Get("templateId").String(),
+			ResultID:   entry.Get("resultId").String(),
+		})
+		return true
+	})
+
+	failures := make(DeviceTemplateErrors, 0)
+	gjson.GetBytes(body, "data.errors").ForEach(func(_, entry gjson.Result) bool {
+		failure := DeviceTemplateError{TemplateID: entry.Get("templateId").String()}
+		entry.Get("messages").ForEach(func(_, message gjson.Result) bool {
+			failure.Messages = append(failure.Messages, message.String())
+			return true
+		})
+		failures = append(failures, failure)
+		return true
+	})
+
+	if len(failures) > 0 {
+		return idMap, failures
+	} else if len(idMap) == 0 {
+		return nil, errors.New(string(body))
+	}
+
+	return idMap, nil
+}
+
+// SearchDevices returns the devices whose display name, network address or
+// host name contains search.
+func (c *Client) SearchDevices(ctx context.Context, search string) ([]DeviceSummary, error) {
+	params := map[string]string{}
+	if search != "" {
+		params["search"] = search
+	}
+
+	body, err := c.getAllPages(ctx, "/devices/-", params, "data.devices")
+	if err != nil {
+		return nil, err
+	}
+
+	devices := make([]DeviceSummary, 0)
+	err = json.Unmarshal(body, &devices)
+	if err != nil {
+		return nil, err
+	}
+
+	return devices, nil
+}
+
+// UpdateDeviceProperties changes the properties of an existing device.
+func (c *Client) UpdateDeviceProperties(ctx context.Context, deviceID string, properties DeviceProperties) error {
+	_, err := c.do(ctx, resty.MethodPut, "/devices/"+url.PathEscape(deviceID)+"/properties", nil, properties)
+
+	return err
+}
+
+// DeleteDevice removes a device. Deleting a missing device is not an error.
+func (c *Client) DeleteDevice(ctx context.Context, deviceID string) error {
+	_, err := c.do(ctx, resty.MethodDelete, "/devices/"+url.PathEscape(deviceID), nil, nil)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+
+	return err
+}
@@ -0,0 +1,5 @@
+// Package wugapi is a client for the WhatsUp Gold REST API.
+//
+// It handles authentication and token renewal, and exposes typed methods
+// for the endpoints used by the Terraform provider.
+package wugapi
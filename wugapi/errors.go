@@ -0,0 +1,32 @@
+package wugapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrNotFound is matched, with errors.Is, by errors about objects that do
+// not exist (anymore) in WUG.
+var ErrNotFound = errors.New("not found")
+
+// Error is returned when WUG answers with a non-successful status code.
+type Error struct {
+	StatusCode int
+	Method     string
+	URL        string
+	Body       string
+}
+
+func (e *Error) Error() string {
+	if len(e.Body) == 0 {
+		return fmt.Sprintf("%s %s: HTTP %d", e.Method, e.URL, e.StatusCode)
+	}
+
+	return e.Body
+}
+
+// Is makes 404 errors match ErrNotFound.
+func (e *Error) Is(target error) bool {
+	return target == ErrNotFound && e.StatusCode == http.StatusNotFound
+}
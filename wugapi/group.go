@@ -0,0 +1,254 @@
+package wugapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/tidwall/gjson"
+)
+
+// Device group types. Dynamic groups hold the devices matching their filter.
+const (
+	StaticDeviceGroup  = "static_group"
+	DynamicDeviceGroup = "dynamic_group"
+)
+
+// DeviceGroup is WUG's internal object.
+type DeviceGroup struct {
+	ID            string `json:"id,omitempty"`
+	ParentGroupID string `json:"parentGroupId,omitempty"`
+	Name          string `json:"name"`
+	Description   string `json:"description"`
+	GroupType     string `json:"groupType,omitempty"`
+	Filter        string `json:"filter,omitempty"`
+}
+
+// DeviceSummary is WUG's internal object, as listed in a device group or
+// a device search.
+type DeviceSummary struct {
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	NetworkAddress string `json:"networkAddress"`
+	HostName       string `json:"hostName"`
+	Role           string `json:"role"`
+	DeviceType     string `json:"deviceType"`
+	Brand          string `json:"brand"`
+	OS             string `json:"os"`
+}
+
+// DeviceGroupTree indexes every device group by ID, to resolve group paths.
+type DeviceGroupTree map[string]DeviceGroup
+
+func deviceGroupPath(groupID string) string {
+	return "/device-groups/" + url.PathEscape(groupID)
+}
+
+// CreateDeviceGroup creates a group under parentID, and returns its ID.
+func (c *Client) CreateDeviceGroup(ctx context.Context, parentID string, group DeviceGroup) (string, error) {
+	body, err := c.do(ctx, resty.MethodPost, deviceGroupPath(parentID)+"/newGroup", nil, group)
+	if err != nil {
+		return "", err
+	}
+
+	groupID := gjson.GetBytes(body, "data.groupId").String()
+
+	if len(groupID) == 0 {
+		return "", fmt.Errorf("no group ID in response: %s", string(body))
+	}
+
+	return groupID, nil
+}
+
+// GetDeviceGroup returns a device group.
+func (c *Client) GetDeviceGroup(ctx context.Context, groupID string) (*DeviceGroup, error) {
+	body, err := c.do(ctx, resty.MethodGet, deviceGroupPath(groupID), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	data := gjson.GetBytes(body, "data")
+	if !data.Exists() || data.Type == gjson.Null {
+		return nil, fmt.Errorf("device group %s: %w", groupID, ErrNotFound)
+	}
+
+	var group DeviceGroup
+	err = json.Unmarshal([]byte(data.Raw), &group)
+	if err != nil {
+		return nil, err
+	}
+
+	return &group, nil
+}
+
+// UpdateDeviceGroup renames, describes or moves a device group.
+func (c *Client) UpdateDeviceGroup(ctx context.Context, groupID string, group DeviceGroup) error {
+	/* WUG clears the filter of a dynamic group when the PUT omits it. */
+	var body interface{} = group
+	if group.GroupType == DynamicDeviceGroup {
+		body = struct {
+			DeviceGroup
+			Filter string `json:"filter"`
+		}{group, group.Filter}
+	}
+
+	_, err := c.do(ctx, resty.MethodPut, deviceGroupPath(groupID), nil, body)
+
+	return err
+}
+
+// DeleteDeviceGroup deletes a device group and its subgroups. Deleting a
+// missing group is not an error.
+func (c *Client) DeleteDeviceGroup(ctx context.Context, groupID string) error {
+	_, err := c.do(ctx, resty.MethodDelete, deviceGroupPath(groupID), nil, nil)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+
+	return err
+}
+
+// ListDeviceGroups returns every device group whose name matches search,
+// or every group when search is empty.
+func (c *Client) ListDeviceGroups(ctx context.Context, search string) ([]DeviceGroup, error) {
+	params := map[string]string{}
+	if search != "" {
+		params["search"] = search
+	}
+
+	body, err := c.getAllPages(ctx, "/device-groups/-", params, "data.groups")
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make([]DeviceGroup, 0)
+	err = json.Unmarshal(body, &groups)
+	if err != nil {
+		return nil, err
+	}
+
+	return groups, nil
+}
+
+// ListDeviceGroupDevices returns the devices that are members of a group.
+func (c *Client) ListDeviceGroupDevices(ctx context.Context, groupID string) ([]DeviceSummary, error) {
+	return c.SearchDeviceGroupDevices(ctx, groupID, "", false)
+}
+
+// SearchDeviceGroupDevices returns the devices of a group whose display
+// name, network address or host name contains search, including the devices
+// of its subgroups when recursive is set.
+func (c *Client) SearchDeviceGroupDevices(ctx context.Context, groupID, search string, recursive bool) ([]DeviceSummary, error) {
+	params := map[string]string{}
+	if search != "" {
+		params["search"] = search
+	}
+	if recursive {
+		params["recursive"] = "true"
+	}
+
+	body, err := c.getAllPages(ctx, deviceGroupPath(groupID)+"/devices/-", params, "data.devices")
+	if err != nil {
+		return nil, err
+	}
+
+	devices := make([]DeviceSummary, 0)
+	err = json.Unmarshal(body, &devices)
+	if err != nil {
+		return nil, err
+	}
+
+	return devices, nil
+}
+
+// UpdateDeviceGroupMembers adds devices to, and removes devices from, a
+// static group. The devices themselves are not modified.
+func (c *Client) UpdateDeviceGroupMembers(ctx context.Context, groupID string, add, remove []string) error {
+	body := map[string][]string{
+		"add":    add,
+		"remove": remove,
+	}
+	if add == nil {
+		body["add"] = []string{}
+	}
+	if remove == nil {
+		body["remove"] = []string{}
+	}
+
+	_, err := c.do(ctx, resty.MethodPatch, deviceGroupPath(groupID)+"/devices/-", nil, body)
+
+	return err
+}
+
+// GetDeviceGroupTree lists every device group.
+func (c *Client) GetDeviceGroupTree(ctx context.Context) (DeviceGroupTree, error) {
+	groups, err := c.ListDeviceGroups(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	tree := make(DeviceGroupTree, len(groups))
+	for _, group := range groups {
+		tree[group.ID] = group
+	}
+
+	return tree, nil
+}
+
+// Path returns the names of the group and its ancestors, from the top-level
+// group down, or nil if the group is unknown.
+func (t DeviceGroupTree) Path(groupID string) []string {
+	var path []string
+
+	for id := groupID; ; {
+		group, ok := t[id]
+		if !ok {
+			break
+		}
+		path = append([]string{group.Name}, path...)
+
+		/* Guard against a cycle in a corrupted tree. */
+		if len(path) > len(t) {
+			return nil
+		}
+		id = group.ParentGroupID
+	}
+
+	return path
+}
+
+// Find returns the group at the given path of names, from the top-level group
+// down.
+func (t DeviceGroupTree) Find(path []string) (DeviceGroup, error) {
+	var found []DeviceGroup
+
+	for id, group := range t {
+		if equalPaths(t.Path(id), path) {
+			found = append(found, group)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return DeviceGroup{}, fmt.Errorf("device group %q: %w", path, ErrNotFound)
+	case 1:
+		return found[0], nil
+	default:
+		return DeviceGroup{}, fmt.Errorf("device group %q is ambiguous, %d groups have this path", path, len(found))
+	}
+}
+
+func equalPaths(a, b []string) bool {
+	if len(a) != len(b) || len(a) == 0 {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,76 @@
+package wugapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/tidwall/gjson"
+)
+
+// DeviceInterface is WUG's internal object.
+type DeviceInterface struct {
+	ID                   string `json:"id,omitempty"`
+	IsDefault            bool   `json:"defaultInterface"`
+	PollUsingNetworkName bool   `json:"pollUsingNetworkName"`
+	NetworkAddress       string `json:"networkAddress"`
+	NetworkName          string `json:"networkName"`
+}
+
+func deviceInterfacePath(deviceID, interfaceID string) string {
+	return "/devices/" + url.PathEscape(deviceID) + "/interfaces/" + url.PathEscape(interfaceID)
+}
+
+// ListDeviceInterfaces returns the interfaces of a device.
+func (c *Client) ListDeviceInterfaces(ctx context.Context, deviceID string) ([]DeviceInterface, error) {
+	body, err := c.getAllPages(ctx, deviceInterfacePath(deviceID, "-"), nil, "data.interfaces")
+	if err != nil {
+		return nil, err
+	}
+
+	interfaces := make([]DeviceInterface, 0)
+	err = json.Unmarshal(body, &interfaces)
+	if err != nil {
+		return nil, err
+	}
+
+	return interfaces, nil
+}
+
+// AddDeviceInterface adds an interface to a device, and returns its ID.
+func (c *Client) AddDeviceInterface(ctx context.Context, deviceID string, iface DeviceInterface) (string, error) {
+	body, err := c.do(ctx, resty.MethodPost, deviceInterfacePath(deviceID, "-"), nil, iface)
+	if err != nil {
+		return "", err
+	}
+
+	interfaceID := gjson.GetBytes(body, "data.interfaceId").String()
+
+	if len(interfaceID) == 0 {
+		return "", fmt.Errorf("no interface ID in response: %s", string(body))
+	}
+
+	return interfaceID, nil
+}
+
+// UpdateDeviceInterface changes an interface of a device. Making it the
+// default interface clears the flag on the other ones.
+func (c *Client) UpdateDeviceInterface(ctx context.Context, deviceID, interfaceID string, iface DeviceInterface) error {
+	_, err := c.do(ctx, resty.MethodPut, deviceInterfacePath(deviceID, interfaceID), nil, iface)
+
+	return err
+}
+
+// RemoveDeviceInterface removes an interface from a device. Removing a
+// missing interface is not an error.
+func (c *Client) RemoveDeviceInterface(ctx context.Context, deviceID, interfaceID string) error {
+	_, err := c.do(ctx, resty.MethodDelete, deviceInterfacePath(deviceID, interfaceID), nil, nil)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+
+	return err
+}
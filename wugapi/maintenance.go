@@ -0,0 +1,135 @@
+package wugapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/tidwall/gjson"
+)
+
+// Kinds of maintenance targets.
+const (
+	MaintenanceDevice = "device"
+	MaintenanceGroup  = "group"
+)
+
+// Recurrences of a maintenance schedule.
+const (
+	RecurrenceNone    = "none"
+	RecurrenceDaily   = "daily"
+	RecurrenceWeekly  = "weekly"
+	RecurrenceMonthly = "monthly"
+)
+
+// Maintenance is WUG's internal object. InMaintenance is read-only, and also
+// reflects the schedules.
+type Maintenance struct {
+	Enabled       bool   `json:"enabled"`
+	Reason        string `json:"reason"`
+	EndUtc        string `json:"endUtc,omitempty"`
+	InMaintenance bool   `json:"inMaintenance,omitempty"`
+}
+
+// MaintenanceSchedule is WUG's internal object.
+type MaintenanceSchedule struct {
+	StartUtc   string `json:"startUtc"`
+	EndUtc     string `json:"endUtc"`
+	Recurrence string `json:"recurrence"`
+}
+
+func maintenancePath(kind, id string) (string, error) {
+	switch kind {
+	case MaintenanceDevice:
+		return "/devices/" + url.PathEscape(id) + "/config/maintenance", nil
+	case MaintenanceGroup:
+		return deviceGroupPath(id) + "/config/maintenance", nil
+	default:
+		return "", fmt.Errorf("unsupported maintenance target: %s", kind)
+	}
+}
+
+// GetMaintenance returns the maintenance mode of a device or a group.
+func (c *Client) GetMaintenance(ctx context.Context, kind, id string) (*Maintenance, error) {
+	path, err := maintenancePath(kind, id)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := c.do(ctx, resty.MethodGet, path, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	data := gjson.GetBytes(body, "data")
+	if !data.Exists() || data.Type == gjson.Null {
+		return nil, fmt.Errorf("maintenance of %s %s: %w", kind, id, ErrNotFound)
+	}
+
+	var maintenance Maintenance
+	err = json.Unmarshal([]byte(data.Raw), &maintenance)
+	if err != nil {
+		return nil, err
+	}
+
+	return &maintenance, nil
+}
+
+// SetMaintenance turns the maintenance mode of a device or a group on or
+// off, until EndUtc when set.
+func (c *Client) SetMaintenance(ctx context.Context, kind, id string, maintenance Maintenance) error {
+	path, err := maintenancePath(kind, id)
+	if err != nil {
+		return err
+	}
+
+	maintenance.InMaintenance = false
+	_, err = c.do(ctx, resty.MethodPut, path, nil, maintenance)
+
+	return err
+}
+
+// GetMaintenanceSchedules returns the maintenance windows of a device or a
+// group.
+func (c *Client) GetMaintenanceSchedules(ctx context.Context, kind, id string) ([]MaintenanceSchedule, error) {
+	path, err := maintenancePath(kind, id)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := c.do(ctx, resty.MethodGet, path+"/schedule", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	schedules := make([]MaintenanceSchedule, 0)
+	if raw := gjson.GetBytes(body, "data.schedules"); raw.Exists() && raw.Type != gjson.Null {
+		err = json.Unmarshal([]byte(raw.Raw), &schedules)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return schedules, nil
+}
+
+// SetMaintenanceSchedules replaces the maintenance windows of a device or a
+// group.
+func (c *Client) SetMaintenanceSchedules(ctx context.Context, kind, id string, schedules []MaintenanceSchedule) error {
+	path, err := maintenancePath(kind, id)
+	if err != nil {
+		return err
+	}
+
+	if schedules == nil {
+		schedules = []MaintenanceSchedule{}
+	}
+
+	_, err = c.do(ctx, resty.MethodPut, path+"/schedule", nil, map[string]interface{}{
+		"schedules": schedules,
+	})
+
+	return err
+}
@@ -0,0 +1,58 @@
+package wugapi_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/nerimcloud/terraform-provider-wug/wugapi"
+	"github.com/nerimcloud/terraform-provider-wug/wugapi/wugtest"
+)
+
+func TestMaintenanceLifecycle(t *testing.T) {
+	srv := wugtest.NewServer()
+	defer srv.Close()
+
+	ctx := context.Background()
+	client := newTestClient(t, srv)
+
+	deviceID := srv.AddDevice(wugtest.Object{"displayName": "web-01"})
+
+	err := client.SetMaintenance(ctx, wugapi.MaintenanceDevice, deviceID, wugapi.Maintenance{Enabled: true, Reason: "Patching"})
+	if err != nil {
+		t.Fatalf("SetMaintenance: %s", err)
+	}
+
+	maintenance, err := client.GetMaintenance(ctx, wugapi.MaintenanceDevice, deviceID)
+	if err != nil {
+		t.Fatalf("GetMaintenance: %s", err)
+	}
+	if !maintenance.Enabled || maintenance.Reason != "Patching" {
+		t.Errorf("unexpected maintenance: %#v", maintenance)
+	}
+
+	srv.DeleteDevice(deviceID)
+	if _, err := client.GetMaintenance(ctx, wugapi.MaintenanceDevice, deviceID); !errors.Is(err, wugapi.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a deleted device, got %v", err)
+	}
+}
+
+func TestGetMaintenanceWithoutData(t *testing.T) {
+	for name, body := range map[string]string{
+		"missing": `{}`,
+		"null":    `{"data": null}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			srv := wugtest.NewServer()
+			defer srv.Close()
+
+			client := newTestClient(t, srv)
+			srv.Fail(http.MethodGet, "/devices/1/config/maintenance", http.StatusOK, body)
+
+			if _, err := client.GetMaintenance(context.Background(), wugapi.MaintenanceDevice, "1"); !errors.Is(err, wugapi.ErrNotFound) {
+				t.Errorf("expected ErrNotFound, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package wugapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/tidwall/gjson"
+)
+
+// MonitorActiveParameters is WUG's internal object.
+type MonitorActiveParameters struct {
+	CriticalOrder          int    `json:"criticalOrder,omitempty"`
+	ActionPolicyName       string `json:"actionPolicyName,omitempty"`
+	ActionPolicyId         string `json:"actionPolicyId,omitempty"`
+	Comment                string `json:"comment,omitempty"`
+	Argument               string `json:"argument,omitempty"`
+	PollingIntervalSeconds int    `json:"pollingIntervalSeconds,omitempty"`
+	InterfaceId            string `json:"interfaceId,string,omitempty"`
+}
+
+// MonitorPerformanceParameters is WUG's internal object.
+type MonitorPerformanceParameters struct {
+	PollingIntervalMinutes int `json:"pollingIntervalMinutes,omitempty"`
+}
+
+// MonitorTemplate is WUG's internal object.
+type MonitorTemplate struct {
+	Type               string                       `json:"type,omitempty"`
+	MonitorTypeClassId string                       `json:"monitorTypeClassId,omitempty"`
+	MonitorTypeId      string                       `json:"monitorType,omitempty"`
+	MonitorTypeName    string                       `json:"monitorTypeName,omitempty"`
+	Active             MonitorActiveParameters      `json:"active,omitempty"`
+	Performance        MonitorPerformanceParameters `json:"performance,omitempty"`
+}
+
+// MonitorInfo is WUG's internal object.
+type MonitorInfo struct {
+	Type        string `json:"type,omitempty"`
+	Search      string `json:"search,omitempty"`
+	ClassId     string `json:"classId,omitempty"`
+	MonitorName string `json:"monitorName,omitempty"`
+}
+
+// MonitorTypeInfo is WUG's internal object.
+type MonitorTypeInfo struct {
+	ClassId  string `json:"classId,omitempty"`
+	BaseType string `json:"baseType,omitempty"`
+}
+
+// MonitorSearchTemplate is WUG's internal object.
+type MonitorSearchTemplate struct {
+	MonitorId       string          `json:"monitorId,omitempty"`
+	Name            string          `json:"name,omitempty"`
+	Description     string          `json:"description,omitempty"`
+	Id              string          `json:"id,omitempty"`
+	MonitorTypeInfo MonitorTypeInfo `json:"monitorTypeInfo,omitempty"`
+}
+
+// DeviceMonitor is a monitor assignment of a device.
+type DeviceMonitor struct {
+	ID string `json:"id,omitempty"`
+	MonitorTemplate
+}
+
+func deviceMonitorPath(deviceID, assignmentID string) string {
+	return "/devices/" + url.PathEscape(deviceID) + "/monitors/" + url.PathEscape(assignmentID)
+}
+
+// ListDeviceMonitors returns the monitor assignments of a device.
+func (c *Client) ListDeviceMonitors(ctx context.Context, deviceID string) ([]DeviceMonitor, error) {
+	body, err := c.getAllPages(ctx, deviceMonitorPath(deviceID, "-"), nil, "data.monitors")
+	if err != nil {
+		return nil, err
+	}
+
+	monitors := make([]DeviceMonitor, 0)
+	err = json.Unmarshal(body, &monitors)
+	if err != nil {
+		return nil, err
+	}
+
+	return monitors, nil
+}
+
+// AddDeviceMonitor assigns a monitor to a device, and returns the assignment ID.
+func (c *Client) AddDeviceMonitor(ctx context.Context, deviceID string, monitor MonitorTemplate) (string, error) {
+	body, err := c.do(ctx, resty.MethodPost, deviceMonitorPath(deviceID, "-"), nil, monitor)
+	if err != nil {
+		return "", err
+	}
+
+	assignmentID := gjson.GetBytes(body, "data.idMap.0.resultId").String()
+
+	if len(assignmentID) == 0 {
+		return "", fmt.Errorf("no assignment ID in response: %s", string(body))
+	}
+
+	return assignmentID, nil
+}
+
+// GetDeviceMonitor returns a monitor assignment of a device.
+func (c *Client) GetDeviceMonitor(ctx context.Context, deviceID, assignmentID string) (*MonitorTemplate, error) {
+	body, err := c.do(ctx, resty.MethodGet, deviceMonitorPath(deviceID, assignmentID), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	data := gjson.GetBytes(body, "data")
+	if !data.Exists() || data.Type == gjson.Null {
+		return nil, fmt.Errorf("monitor assignment %s of device %s: %w", assignmentID, deviceID, ErrNotFound)
+	}
+
+	var monitor MonitorTemplate
+	err = json.Unmarshal([]byte(data.Raw), &monitor)
+	if err != nil {
+		return nil, err
+	}
+
+	return &monitor, nil
+}
+
+// UpdateDeviceMonitor changes the parameters of an existing monitor
+// assignment, keeping its ID and state.
+func (c *Client) UpdateDeviceMonitor(ctx context.Context, deviceID, assignmentID string, monitor MonitorTemplate) error {
+	_, err := c.do(ctx, resty.MethodPut, deviceMonitorPath(deviceID, assignmentID), nil, monitor)
+
+	return err
+}
+
+// RemoveDeviceMonitor removes a monitor assignment from a device. Removing a
+// missing assignment is not an error.
+func (c *Client) RemoveDeviceMonitor(ctx context.Context, deviceID, assignmentID string) error {
+	_, err := c.do(ctx, resty.MethodDelete, deviceMonitorPath(deviceID, assignmentID), nil, nil)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+
+	return err
+}
+
+// SearchMonitors looks up monitors of the given type ("active" or
+// "performance") in the monitor library.
+func (c *Client) SearchMonitors(ctx context.Context, monitorType, search string) ([]MonitorSearchTemplate, error) {
+	params := map[string]string{
+		"type":                  monitorType,
+		"search":                search,
+		"includeDeviceMonitors": "true",
+		"includeSystemMonitors": "true",
+		"includeCoreMonitors":   "true",
+	}
+
+	var path string
+	switch monitorType {
+	case "active":
+		path = "data.activeMonitors"
+	case "performance":
+		path = "data.performanceMonitors"
+	default:
+		return nil, fmt.Errorf("unsupported monitor type: %s", monitorType)
+	}
+
+	body, err := c.getAllPages(ctx, "/monitors/-", params, path)
+	if err != nil {
+		return nil, err
+	}
+
+	monitors := make([]MonitorSearchTemplate, 0)
+	err = json.Unmarshal(body, &monitors)
+	if err != nil {
+		return nil, err
+	}
+
+	return monitors, nil
+}
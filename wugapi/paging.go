@@ -0,0 +1,46 @@
+package wugapi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/tidwall/gjson"
+)
+
+/* Upper bound on the pages of a single listing, in case a server keeps
+ * returning a nextPageId. */
+const maxPages = 1000
+
+// getAllPages GETs a paged listing, following paging.nextPageId, and returns
+// the items found at itemsPath of every page as a single JSON array.
+func (c *Client) getAllPages(ctx context.Context, path string, query map[string]string, itemsPath string) ([]byte, error) {
+	params := make(map[string]string, len(query)+1)
+	for key, value := range query {
+		params[key] = value
+	}
+
+	items := make([]string, 0)
+
+	for page := 0; page < maxPages; page++ {
+		body, err := c.do(ctx, resty.MethodGet, path, params, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		gjson.GetBytes(body, itemsPath).ForEach(func(_, item gjson.Result) bool {
+			items = append(items, item.Raw)
+			return true
+		})
+
+		next := gjson.GetBytes(body, "paging.nextPageId").String()
+		if next == "" {
+			return []byte("[" + strings.Join(items, ",") + "]"), nil
+		}
+
+		params["pageId"] = next
+	}
+
+	return nil, fmt.Errorf("%s: more than %d pages, giving up", path, maxPages)
+}
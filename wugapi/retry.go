@@ -0,0 +1,119 @@
+package wugapi
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+const (
+	// DefaultMaxRetries is the provider default for Config.MaxRetries.
+	DefaultMaxRetries = 3
+	// DefaultRetryMaxWait is used when Config.RetryMaxWait is not set.
+	DefaultRetryMaxWait = 30 * time.Second
+
+	retryMinWait = 1 * time.Second
+)
+
+// idempotentMethods can be replayed whatever happened to the first attempt.
+var idempotentMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodOptions: true,
+	http.MethodPut:     true,
+	http.MethodDelete:  true,
+}
+
+// configureRetries sets up exponential backoff with jitter on transient
+// failures. Non-idempotent calls (POST, PATCH) are only retried when WUG
+// tells us it did not process them (429, 503) or when the connection could
+// not be established at all.
+func (c *Client) configureRetries() {
+	maxRetries := c.Config.MaxRetries
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
+
+	maxWait := c.Config.RetryMaxWait
+	if maxWait <= 0 {
+		maxWait = DefaultRetryMaxWait
+	}
+
+	/* Keep at least one retry for the 401 token renewal. */
+	count := maxRetries
+	if count < 1 {
+		count = 1
+	}
+
+	c.Resty.SetRetryCount(count)
+	c.Resty.SetRetryWaitTime(retryMinWait)
+	c.Resty.SetRetryMaxWaitTime(maxWait)
+	c.Resty.SetRetryAfter(retryAfter)
+	c.Resty.AddRetryCondition(c.retryUnauthorized)
+	c.Resty.AddRetryCondition(func(resp *resty.Response, err error) bool {
+		if resp == nil || resp.Request == nil || resp.Request.Attempt > maxRetries {
+			return false
+		}
+
+		if !isTransient(resp, err) {
+			return false
+		}
+
+		logger.Warnf("Transient failure on %s %s (attempt %d/%d), retrying",
+			resp.Request.Method, resp.Request.URL, resp.Request.Attempt, maxRetries+1)
+
+		return true
+	})
+}
+
+// isTransient tells whether a failed call may be safely retried.
+func isTransient(resp *resty.Response, err error) bool {
+	idempotent := idempotentMethods[resp.Request.Method]
+
+	if err != nil {
+		/* Nothing reached the server, always safe. */
+		var opErr *net.OpError
+		if errors.As(err, &opErr) && opErr.Op == "dial" {
+			return true
+		}
+		if errors.Is(err, syscall.ECONNREFUSED) {
+			return true
+		}
+
+		/* The server may have processed the request before dropping us. */
+		if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return idempotent
+		}
+
+		return false
+	}
+
+	switch resp.StatusCode() {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return idempotent
+	}
+
+	return false
+}
+
+// retryAfter honours the Retry-After header (in seconds) when WUG sends one.
+func retryAfter(_ *resty.Client, resp *resty.Response) (time.Duration, error) {
+	if resp == nil || resp.RawResponse == nil {
+		return 0, nil
+	}
+
+	seconds, err := strconv.Atoi(resp.Header().Get("Retry-After"))
+	if err != nil || seconds <= 0 {
+		return 0, nil
+	}
+
+	return time.Duration(seconds) * time.Second, nil
+}
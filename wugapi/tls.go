@@ -0,0 +1,72 @@
+package wugapi
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+// readPEM accepts either inline PEM content or a path to a PEM file.
+func readPEM(value string) ([]byte, error) {
+	if strings.Contains(value, "-----BEGIN") {
+		return []byte(value), nil
+	}
+
+	return ioutil.ReadFile(value)
+}
+
+// tlsConfig builds the TLS settings for the WUG endpoint.
+func (c *Config) tlsConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: c.InsecureFlag,
+		ServerName:         c.TLSServerName,
+	}
+
+	if c.CACertFile != "" || c.CACertPEM != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+
+		var ca []byte
+		if c.CACertPEM != "" {
+			ca = []byte(c.CACertPEM)
+		} else if ca, err = ioutil.ReadFile(c.CACertFile); err != nil {
+			return nil, fmt.Errorf("reading CA certificate: %s", err)
+		}
+
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, errors.New("no valid certificate found in the CA bundle")
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	if c.ClientCert != "" || c.ClientKey != "" {
+		if c.ClientCert == "" || c.ClientKey == "" {
+			return nil, errors.New("client_cert and client_key must be set together")
+		}
+
+		certPEM, err := readPEM(c.ClientCert)
+		if err != nil {
+			return nil, fmt.Errorf("reading client certificate: %s", err)
+		}
+
+		keyPEM, err := readPEM(c.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("reading client key: %s", err)
+		}
+
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %s", err)
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
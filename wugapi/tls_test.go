@@ -0,0 +1,175 @@
+package wugapi
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testCertificate returns a self-signed certificate for wug.example.internal
+// and its key, both as PEM.
+func testCertificate(t *testing.T) (*x509.Certificate, string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %s", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "wug.example.internal"},
+		DNSNames:              []string{"wug.example.internal"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("CreateCertificate: %s", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("ParseCertificate: %s", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalECPrivateKey: %s", err)
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+
+	return cert, string(certPEM), string(keyPEM)
+}
+
+// writeTemp writes content to a file of dir, and returns its path.
+func writeTemp(t *testing.T, dir, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("WriteFile: %s", err)
+	}
+
+	return path
+}
+
+func TestTLSConfigInsecure(t *testing.T) {
+	config, err := (&Config{InsecureFlag: true, TLSServerName: "wug.example.internal"}).tlsConfig()
+	if err != nil {
+		t.Fatalf("tlsConfig: %s", err)
+	}
+
+	if !config.InsecureSkipVerify {
+		t.Error("expected certificate verification to be skipped")
+	}
+	if config.ServerName != "wug.example.internal" {
+		t.Errorf("expected server name wug.example.internal, got %q", config.ServerName)
+	}
+	if config.RootCAs != nil || len(config.Certificates) != 0 {
+		t.Error("expected no CA nor client certificate")
+	}
+}
+
+func TestTLSConfigCA(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wugapi")
+	if err != nil {
+		t.Fatalf("TempDir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	cert, certPEM, _ := testCertificate(t)
+
+	for name, config := range map[string]*Config{
+		"file":   {CACertFile: writeTemp(t, dir, "ca.pem", certPEM)},
+		"inline": {CACertPEM: certPEM},
+	} {
+		t.Run(name, func(t *testing.T) {
+			tlsConfig, err := config.tlsConfig()
+			if err != nil {
+				t.Fatalf("tlsConfig: %s", err)
+			}
+
+			if tlsConfig.InsecureSkipVerify {
+				t.Error("expected certificates to be verified")
+			}
+			if _, err := cert.Verify(x509.VerifyOptions{Roots: tlsConfig.RootCAs, DNSName: "wug.example.internal"}); err != nil {
+				t.Errorf("expected the CA to be trusted: %s", err)
+			}
+		})
+	}
+
+	for name, config := range map[string]*Config{
+		"missing file": {CACertFile: filepath.Join(dir, "missing.pem")},
+		"invalid PEM":  {CACertPEM: "-----BEGIN CERTIFICATE-----\nnope\n-----END CERTIFICATE-----\n"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := config.tlsConfig(); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestTLSConfigClientCertificate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wugapi")
+	if err != nil {
+		t.Fatalf("TempDir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	_, certPEM, keyPEM := testCertificate(t)
+	certFile := writeTemp(t, dir, "client.crt", certPEM)
+	keyFile := writeTemp(t, dir, "client.key", keyPEM)
+
+	for name, config := range map[string]*Config{
+		"files":  {ClientCert: certFile, ClientKey: keyFile},
+		"inline": {ClientCert: certPEM, ClientKey: keyPEM},
+		"mixed":  {ClientCert: certFile, ClientKey: keyPEM},
+	} {
+		t.Run(name, func(t *testing.T) {
+			tlsConfig, err := config.tlsConfig()
+			if err != nil {
+				t.Fatalf("tlsConfig: %s", err)
+			}
+
+			if len(tlsConfig.Certificates) != 1 {
+				t.Errorf("expected one client certificate, got %d", len(tlsConfig.Certificates))
+			}
+		})
+	}
+
+	for name, config := range map[string]*Config{
+		"certificate only": {ClientCert: certPEM},
+		"key only":         {ClientKey: keyFile},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := config.tlsConfig()
+			if err == nil || !strings.Contains(err.Error(), "must be set together") {
+				t.Errorf("expected the missing half to be reported, got %v", err)
+			}
+		})
+	}
+
+	/* The key does not match the certificate. */
+	_, _, otherKeyPEM := testCertificate(t)
+	if _, err := (&Config{ClientCert: certPEM, ClientKey: otherKeyPEM}).tlsConfig(); err == nil {
+		t.Error("expected mismatched certificate and key to fail")
+	}
+}
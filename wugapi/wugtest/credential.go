@@ -0,0 +1,151 @@
+package wugtest
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Credential is an entry of the fake credential library.
+type Credential struct {
+	ID   string
+	Type string
+	Name string
+}
+
+// AddCredential adds a credential to the library listed by /credentials/-.
+func (s *Server) AddCredential(credential Credential) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.credentials = append(s.credentials, credential)
+}
+
+// DeviceCredentials returns the "<type>/<name>" of the credentials assigned
+// to a device, sorted by credential ID.
+func (s *Server) DeviceCredentials(deviceID string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	assigned := make([]string, 0)
+	if device, ok := s.devices[deviceID]; ok {
+		for _, credential := range sortedObjects(device.Credentials) {
+			assigned = append(assigned, credential["type"].(string)+"/"+credential["name"].(string))
+		}
+	}
+
+	return assigned
+}
+
+func credentialObject(credential Credential) Object {
+	return Object{"id": credential.ID, "type": credential.Type, "name": credential.Name}
+}
+
+/* findCredential looks a credential up by type, case-insensitively as WUG
+ * does, and name. */
+func (s *Server) findCredential(credentialType, name string) (Credential, bool) {
+	for _, credential := range s.credentials {
+		if strings.EqualFold(credential.Type, credentialType) && credential.Name == name {
+			return credential, true
+		}
+	}
+
+	return Credential{}, false
+}
+
+/* unknownCredentials returns an error message for each credential of a
+ * template missing from the library. */
+func (s *Server) unknownCredentials(template Object) []string {
+	messages := make([]string, 0)
+
+	credentials, _ := template["credentials"].([]interface{})
+	for _, item := range credentials {
+		credential, _ := item.(Object)
+		credentialType, _ := credential["credentialType"].(string)
+		name, _ := credential["credential"].(string)
+
+		if _, ok := s.findCredential(credentialType, name); !ok {
+			messages = append(messages, "credential "+name+" of type "+credentialType+" not found")
+		}
+	}
+
+	return messages
+}
+
+/* assignCredential assigns a credential to a device, replacing the one of
+ * the same type: a device has at most one credential per type. */
+func assignCredential(device *Device, credential Credential) {
+	for id, assigned := range device.Credentials {
+		if strings.EqualFold(assigned["type"].(string), credential.Type) {
+			delete(device.Credentials, id)
+		}
+	}
+
+	device.Credentials[credential.ID] = credentialObject(credential)
+}
+
+func (s *Server) listCredentials(w http.ResponseWriter, query url.Values) {
+	credentials := make([]Object, 0, len(s.credentials))
+	for _, credential := range s.credentials {
+		if t := query.Get("type"); t == "" || strings.EqualFold(t, credential.Type) {
+			credentials = append(credentials, credentialObject(credential))
+		}
+	}
+
+	start, end, paging := s.page(query, len(credentials))
+
+	writeJSON(w, http.StatusOK, Object{
+		"paging": paging,
+		"data":   Object{"credentials": credentials[start:end]},
+	})
+}
+
+func (s *Server) listDeviceCredentials(w http.ResponseWriter, deviceID string, query url.Values) {
+	device, ok := s.devices[deviceID]
+	if !ok {
+		writeError(w, http.StatusNotFound, "device "+deviceID+" not found")
+		return
+	}
+
+	credentials := sortedObjects(device.Credentials)
+	start, end, paging := s.page(query, len(credentials))
+
+	writeJSON(w, http.StatusOK, Object{
+		"paging": paging,
+		"data":   Object{"credentials": credentials[start:end]},
+	})
+}
+
+func (s *Server) assignDeviceCredential(w http.ResponseWriter, deviceID, credentialID string) {
+	device, ok := s.devices[deviceID]
+	if !ok {
+		writeError(w, http.StatusNotFound, "device "+deviceID+" not found")
+		return
+	}
+
+	for _, credential := range s.credentials {
+		if credential.ID == credentialID {
+			assignCredential(device, credential)
+			writeData(w, Object{"success": true})
+			return
+		}
+	}
+
+	writeError(w, http.StatusBadRequest, "credential "+credentialID+" not found")
+}
+
+func (s *Server) deleteDeviceCredential(w http.ResponseWriter, deviceID, credentialID string) {
+	device, ok := s.devices[deviceID]
+	if !ok {
+		writeError(w, http.StatusNotFound, "device "+deviceID+" not found")
+		return
+	}
+
+	if _, ok := device.Credentials[credentialID]; !ok {
+		writeError(w, http.StatusNotFound, "credential "+credentialID+" is not assigned to device "+deviceID)
+		return
+	}
+
+	delete(device.Credentials, credentialID)
+	writeData(w, Object{"success": true})
+}
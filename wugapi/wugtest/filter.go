@@ -0,0 +1,57 @@
+package wugtest
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	filterAnd    = regexp.MustCompile(`(?i)\s+AND\s+`)
+	filterClause = regexp.MustCompile(`(?i)^\s*(\w+)\s*(=|!=|LIKE)\s*'([^']*)'\s*$`)
+)
+
+// MatchFilter evaluates the filter of a dynamic group against a device, as
+// listed in a group (name, networkAddress, hostName, role, brand, os).
+//
+// Real WUG filters are SQL. The fake server understands a small subset,
+// clauses joined with AND, each comparing a field with a quoted value:
+//
+//	role = 'Windows Server' AND networkAddress LIKE '10.0.1.%'
+//
+// Comparisons ignore case, and % matches any sequence in LIKE patterns.
+func MatchFilter(filter string, device Object) (bool, error) {
+	if strings.TrimSpace(filter) == "" {
+		return false, fmt.Errorf("empty filter")
+	}
+
+	match := true
+
+	for _, clause := range filterAnd.Split(filter, -1) {
+		parts := filterClause.FindStringSubmatch(clause)
+		if parts == nil {
+			return false, fmt.Errorf("unsupported clause %q", clause)
+		}
+
+		field, op, value := parts[1], strings.ToUpper(parts[2]), strings.ToLower(parts[3])
+		actual := strings.ToLower(fmt.Sprint(device[field]))
+		if device[field] == nil {
+			actual = ""
+		}
+
+		var ok bool
+		switch op {
+		case "=":
+			ok = actual == value
+		case "!=":
+			ok = actual != value
+		case "LIKE":
+			pattern := "^" + strings.ReplaceAll(regexp.QuoteMeta(value), "%", ".*") + "$"
+			ok = regexp.MustCompile(pattern).MatchString(actual)
+		}
+
+		match = match && ok
+	}
+
+	return match, nil
+}
@@ -0,0 +1,253 @@
+package wugtest
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Kinds of maintenance targets, as in the wugapi package.
+const (
+	MaintenanceDevice = "device"
+	MaintenanceGroup  = "group"
+)
+
+type maintenance struct {
+	enabled   bool
+	reason    string
+	end       string
+	schedules []Object
+}
+
+// InMaintenance reports whether a device or a group is in maintenance now,
+// either turned on or within a scheduled window.
+func (s *Server) InMaintenance(kind, id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.inMaintenance(kind+"/"+id, s.now())
+}
+
+// Advance moves the clock of maintenance modes and windows forward, e.g.
+// past the end time of a maintenance mode.
+func (s *Server) Advance(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.offset += d
+}
+
+func (s *Server) now() time.Time {
+	return time.Now().Add(s.offset)
+}
+
+/* expireMaintenance turns the maintenance mode off once its end time has
+ * passed, as WUG does. The end time is kept. */
+func (s *Server) expireMaintenance(m *maintenance) {
+	if !m.enabled || m.end == "" {
+		return
+	}
+
+	if end, err := time.Parse(time.RFC3339, m.end); err == nil && !s.now().Before(end) {
+		m.enabled = false
+	}
+}
+
+// MaintenanceSchedules returns the maintenance windows of a device or a group.
+func (s *Server) MaintenanceSchedules(kind, id string) []Object {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if m, ok := s.maintenance[kind+"/"+id]; ok {
+		return m.schedules
+	}
+
+	return nil
+}
+
+// SetMaintenance turns the maintenance mode of a device or a group on or off
+// out of band.
+func (s *Server) SetMaintenance(kind, id string, enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.maintenanceOf(kind + "/" + id).enabled = enabled
+}
+
+func (s *Server) maintenanceOf(key string) *maintenance {
+	if s.maintenance[key] == nil {
+		s.maintenance[key] = &maintenance{schedules: make([]Object, 0)}
+	}
+
+	return s.maintenance[key]
+}
+
+/* maintenanceTarget checks that the target exists, and returns its key. */
+func (s *Server) maintenanceTarget(w http.ResponseWriter, kind, id string) (string, bool) {
+	exists := false
+	switch kind {
+	case MaintenanceDevice:
+		_, exists = s.devices[id]
+	case MaintenanceGroup:
+		_, exists = s.groups[id]
+	}
+
+	if !exists {
+		writeError(w, http.StatusNotFound, kind+" "+id+" not found")
+		return "", false
+	}
+
+	return kind + "/" + id, true
+}
+
+func (s *Server) inMaintenance(key string, now time.Time) bool {
+	m, ok := s.maintenance[key]
+	if !ok {
+		return false
+	}
+
+	if m.enabled {
+		end, err := time.Parse(time.RFC3339, m.end)
+		if m.end == "" || (err == nil && now.Before(end)) {
+			return true
+		}
+	}
+
+	for _, schedule := range m.schedules {
+		if inWindow(schedule, now) {
+			return true
+		}
+	}
+
+	return false
+}
+
+/* inWindow reports whether now falls in an occurrence of a valid schedule.
+ * Occurrences are shifted from the first window, AddDate normalizes month
+ * ends. */
+func inWindow(schedule Object, now time.Time) bool {
+	start, _ := time.Parse(time.RFC3339, fmt.Sprint(schedule["startUtc"]))
+	end, _ := time.Parse(time.RFC3339, fmt.Sprint(schedule["endUtc"]))
+	length := end.Sub(start)
+
+	var months, days int
+	switch schedule["recurrence"] {
+	case "daily":
+		days = 1
+	case "weekly":
+		days = 7
+	case "monthly":
+		months = 1
+	}
+
+	for n := 0; ; n++ {
+		occurrence := start.AddDate(0, n*months, n*days)
+		if occurrence.After(now) {
+			return false
+		}
+		if now.Before(occurrence.Add(length)) {
+			return true
+		}
+		if months == 0 && days == 0 {
+			return false
+		}
+	}
+}
+
+func validateSchedule(schedule Object) error {
+	start, err := time.Parse(time.RFC3339, fmt.Sprint(schedule["startUtc"]))
+	if err != nil {
+		return fmt.Errorf("invalid startUtc: %s", err)
+	}
+	end, err := time.Parse(time.RFC3339, fmt.Sprint(schedule["endUtc"]))
+	if err != nil {
+		return fmt.Errorf("invalid endUtc: %s", err)
+	}
+	if !end.After(start) {
+		return fmt.Errorf("endUtc must be after startUtc")
+	}
+
+	switch schedule["recurrence"] {
+	case "none", "daily", "weekly", "monthly":
+	default:
+		return fmt.Errorf("invalid recurrence: %v", schedule["recurrence"])
+	}
+
+	return nil
+}
+
+func (s *Server) getMaintenance(w http.ResponseWriter, kind, id string) {
+	key, ok := s.maintenanceTarget(w, kind, id)
+	if !ok {
+		return
+	}
+
+	m := s.maintenanceOf(key)
+	s.expireMaintenance(m)
+
+	data := Object{
+		"enabled":       m.enabled,
+		"reason":        m.reason,
+		"inMaintenance": s.inMaintenance(key, s.now()),
+	}
+	if m.end != "" {
+		data["endUtc"] = m.end
+	}
+
+	writeData(w, data)
+}
+
+func (s *Server) setMaintenance(w http.ResponseWriter, kind, id string, body Object) {
+	key, ok := s.maintenanceTarget(w, kind, id)
+	if !ok {
+		return
+	}
+
+	enabled, _ := body["enabled"].(bool)
+	reason, _ := body["reason"].(string)
+	end, _ := body["endUtc"].(string)
+
+	if end != "" {
+		if _, err := time.Parse(time.RFC3339, end); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid endUtc: "+err.Error())
+			return
+		}
+	}
+
+	m := s.maintenanceOf(key)
+	m.enabled, m.reason, m.end = enabled, reason, end
+
+	writeData(w, Object{"success": true})
+}
+
+func (s *Server) getMaintenanceSchedules(w http.ResponseWriter, kind, id string) {
+	key, ok := s.maintenanceTarget(w, kind, id)
+	if !ok {
+		return
+	}
+
+	writeData(w, Object{"schedules": s.maintenanceOf(key).schedules})
+}
+
+func (s *Server) setMaintenanceSchedules(w http.ResponseWriter, kind, id string, body Object) {
+	key, ok := s.maintenanceTarget(w, kind, id)
+	if !ok {
+		return
+	}
+
+	items, _ := body["schedules"].([]interface{})
+	schedules := make([]Object, 0, len(items))
+
+	for i, item := range items {
+		schedule, _ := item.(Object)
+		if err := validateSchedule(schedule); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("schedule %d: %s", i, err))
+			return
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	s.maintenanceOf(key).schedules = schedules
+
+	writeData(w, Object{"success": true})
+}
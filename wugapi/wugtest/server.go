@@ -0,0 +1,1660 @@
+// Package wugtest provides an in-memory WhatsUp Gold API for hermetic tests.
+//
+// The fake server implements the subset of the REST API used by the
+// provider, with the same response envelopes as WUG (data.idMap,
+// data.deviceCount, paging.size...):
+//
+//	srv := wugtest.NewServer()
+//	defer srv.Close()
+//	client, err := wugapi.NewClient(ctx, &wugapi.Config{URL: srv.URL(), User: wugtest.User, Password: wugtest.Password})
+package wugtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Credentials accepted by the fake /token endpoint.
+const (
+	User     = "admin"
+	Password = "secret"
+)
+
+// BasePath is the path prefix of the API, as on a real WUG server.
+const BasePath = "/api/v1"
+
+// Object is a JSON object as stored by the fake server.
+type Object = map[string]interface{}
+
+// Device is a device known to the fake server.
+type Device struct {
+	ID          string
+	Template    Object
+	Interfaces  map[string]Object
+	Credentials map[string]Object
+	Monitors    map[string]Object
+	Attributes  map[string]Object
+
+	/* Monitors as written in the template the device was created with,
+	 * keyed by assignment ID, until the assignment is updated. */
+	templateMonitors map[string]Object
+}
+
+// Root device group, created with the server as on a fresh WUG install.
+const (
+	RootGroupID   = "0"
+	RootGroupName = "My Network"
+)
+
+// DefaultPageSize is the number of items per page of the listings.
+const DefaultPageSize = 25
+
+// Group types, as reported in groupType.
+const (
+	StaticGroup  = "static_group"
+	DynamicGroup = "dynamic_group"
+)
+
+// DeviceGroup is a device group known to the fake server. Dynamic groups
+// hold the devices matching their Filter, see MatchFilter.
+type DeviceGroup struct {
+	ID          string
+	ParentID    string
+	Name        string
+	Description string
+	Type        string
+	Filter      string
+}
+
+// Monitor is an entry of the fake monitor library.
+type Monitor struct {
+	ID      string
+	Type    string
+	Name    string
+	ClassID string
+}
+
+type failure struct {
+	status int
+	body   string
+}
+
+// Server is an in-memory WhatsUp Gold API.
+type Server struct {
+	// TokenLifetime is the expires_in advertised by /token.
+	TokenLifetime time.Duration
+
+	// PageSize is the number of items per page when the call sets no limit.
+	PageSize int
+
+	srv *httptest.Server
+
+	mu            sync.Mutex
+	nextID        int
+	tokens        map[string]time.Time
+	refreshTokens map[string]bool
+	devices       map[string]*Device
+	groups        map[string]*DeviceGroup
+	members       map[string]map[string]bool
+	maintenance   map[string]*maintenance
+	library       []Monitor
+	credentials   []Credential
+	failures      map[string]failure
+	requests      map[string]int
+
+	/* Offset of the maintenance clock, see Advance. */
+	offset time.Duration
+}
+
+// NewServer starts a fake WUG server with a root device group, and small
+// monitor and credential libraries.
+func NewServer() *Server {
+	s := &Server{
+		TokenLifetime: time.Hour,
+		PageSize:      DefaultPageSize,
+		nextID:        1,
+		tokens:        make(map[string]time.Time),
+		refreshTokens: make(map[string]bool),
+		devices:       make(map[string]*Device),
+		groups:        make(map[string]*DeviceGroup),
+		members:       make(map[string]map[string]bool),
+		maintenance:   make(map[string]*maintenance),
+		failures:      make(map[string]failure),
+		requests:      make(map[string]int),
+		library: []Monitor{
+			{ID: "1", Type: "active", Name: "Ping", ClassID: "ping-class"},
+			{ID: "2", Type: "active", Name: "HTTP Content Scan", ClassID: "http-class"},
+			{ID: "3", Type: "performance", Name: "CPU Utilization", ClassID: "cpu-class"},
+		},
+		credentials: []Credential{
+			{ID: "1", Type: "SNMP", Name: "public-v2"},
+			{ID: "2", Type: "SNMPv3", Name: "private-v3"},
+			{ID: "3", Type: "Windows", Name: "domain-admin"},
+		},
+	}
+
+	s.groups[RootGroupID] = &DeviceGroup{ID: RootGroupID, Name: RootGroupName, Type: StaticGroup}
+
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+
+	return s
+}
+
+// URL returns the API endpoint to configure the client with.
+func (s *Server) URL() string {
+	return s.srv.URL + BasePath
+}
+
+// Close shuts the server down.
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// Fail makes every following "METHOD /path" call (path relative to
+// BasePath, e.g. "PATCH /devices/-/config/template") answer with the given
+// status and body, until ClearFailures is called.
+func (s *Server) Fail(method, path string, status int, body string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failures[method+" "+path] = failure{status: status, body: body}
+}
+
+// ClearFailures removes every failure set up with Fail.
+func (s *Server) ClearFailures() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failures = make(map[string]failure)
+}
+
+// Requests returns how many "METHOD /path" calls the server received.
+func (s *Server) Requests(method, path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.requests[method+" "+path]
+}
+
+// ExpireTokens invalidates every access token, refresh tokens stay valid.
+func (s *Server) ExpireTokens() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tokens = make(map[string]time.Time)
+}
+
+// AddMonitor adds a monitor to the library searched by /monitors/-.
+func (s *Server) AddMonitor(monitor Monitor) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.library = append(s.library, monitor)
+}
+
+// Device returns a copy of a device, as stored by the server.
+func (s *Server) Device(id string) (Device, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	device, ok := s.devices[id]
+	if !ok {
+		return Device{}, false
+	}
+
+	return *device, true
+}
+
+// DeviceIDs returns the IDs of every device, sorted.
+func (s *Server) DeviceIDs() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := make([]string, 0, len(s.devices))
+	for id := range s.devices {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	return ids
+}
+
+// AddDevice creates a device out of band, as if it were made in the console.
+func (s *Server) AddDevice(template Object) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.addDevice(template)
+}
+
+// DeleteDevice removes a device out of band.
+func (s *Server) DeleteDevice(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.removeDevice(id)
+}
+
+// DeleteDeviceMonitor removes a monitor assignment out of band.
+func (s *Server) DeleteDeviceMonitor(deviceID, assignmentID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if device, ok := s.devices[deviceID]; ok {
+		delete(device.Monitors, assignmentID)
+		delete(device.templateMonitors, assignmentID)
+	}
+}
+
+// SetDeviceMonitor changes a field of a monitor assignment out of band, e.g.
+// to render it as another WUG version does.
+func (s *Server) SetDeviceMonitor(deviceID, assignmentID, key string, value interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if device, ok := s.devices[deviceID]; ok {
+		if monitor, ok := device.Monitors[assignmentID]; ok {
+			monitor[key] = value
+		}
+	}
+}
+
+// DeviceGroup returns a copy of a device group.
+func (s *Server) DeviceGroup(id string) (DeviceGroup, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	group, ok := s.groups[id]
+	if !ok {
+		return DeviceGroup{}, false
+	}
+
+	return *group, true
+}
+
+// AddDeviceGroup creates a device group out of band, and returns its ID.
+func (s *Server) AddDeviceGroup(parentID, name string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := s.newID()
+	s.groups[id] = &DeviceGroup{ID: id, ParentID: parentID, Name: name, Type: StaticGroup}
+
+	return id
+}
+
+// SetDeviceGroupFilter turns a device group into a dynamic group with the
+// given filter, out of band.
+func (s *Server) SetDeviceGroupFilter(id, filter string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if group, ok := s.groups[id]; ok {
+		group.Type = DynamicGroup
+		group.Filter = filter
+	}
+}
+
+// SetDeviceAttribute sets a custom attribute of a device out of band, and
+// returns its ID.
+func (s *Server) SetDeviceAttribute(deviceID, name, value string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	device := s.devices[deviceID]
+	for id, attribute := range device.Attributes {
+		if attribute["name"] == name {
+			attribute["value"] = value
+			return id
+		}
+	}
+
+	id := s.newID()
+	device.Attributes[id] = Object{"id": id, "name": name, "value": value}
+
+	return id
+}
+
+// GroupMembers returns the IDs of the devices of a group, sorted.
+func (s *Server) GroupMembers(groupID string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.groupMembers(groupID)
+}
+
+// RemoveGroupMember removes a device from a group out of band.
+func (s *Server) RemoveGroupMember(groupID, deviceID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.members[groupID], deviceID)
+}
+
+// DeleteDeviceGroup removes a device group and its subgroups out of band.
+func (s *Server) DeleteDeviceGroup(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deleteGroupTree(id)
+}
+
+func (s *Server) newID() string {
+	id := strconv.Itoa(s.nextID)
+	s.nextID++
+
+	return id
+}
+
+/* addDevice stores a device, and adds it to the groups listed in its
+ * template, creating the missing ones as WUG does. Interfaces, credentials,
+ * monitors and group memberships are then tracked apart, and rendered back
+ * by getTemplate. Credentials missing from the library are ignored. */
+func (s *Server) addDevice(template Object) string {
+	id := s.newID()
+	device := &Device{
+		ID:          id,
+		Template:    template,
+		Interfaces:  make(map[string]Object),
+		Credentials: make(map[string]Object),
+		Monitors:    make(map[string]Object),
+		Attributes:  make(map[string]Object),
+
+		templateMonitors: make(map[string]Object),
+	}
+	s.devices[id] = device
+
+	interfaces, _ := template["interfaces"].([]interface{})
+	for _, item := range interfaces {
+		iface, _ := item.(Object)
+		interfaceID := s.newID()
+		iface["id"] = interfaceID
+		device.Interfaces[interfaceID] = iface
+	}
+	delete(template, "interfaces")
+
+	credentials, _ := template["credentials"].([]interface{})
+	for _, item := range credentials {
+		reference, _ := item.(Object)
+		credentialType, _ := reference["credentialType"].(string)
+		name, _ := reference["credential"].(string)
+
+		if credential, ok := s.findCredential(credentialType, name); ok {
+			assignCredential(device, credential)
+		}
+	}
+	delete(template, "credentials")
+
+	for _, monitorType := range []string{"active", "performance"} {
+		monitors, _ := template[monitorType+"Monitors"].([]interface{})
+		for _, item := range monitors {
+			monitor, _ := item.(Object)
+			assignmentID := s.newID()
+			device.Monitors[assignmentID] = s.monitorAssignment(assignmentID, monitorType, monitor)
+			device.templateMonitors[assignmentID] = monitor
+		}
+		delete(template, monitorType+"Monitors")
+	}
+
+	groups, _ := template["groups"].([]interface{})
+	for _, item := range groups {
+		group, _ := item.(Object)
+		name, _ := group["name"].(string)
+		parents, _ := group["parents"].([]interface{})
+
+		path := make([]string, 0, len(parents)+1)
+		for _, parent := range parents {
+			path = append(path, fmt.Sprint(parent))
+		}
+
+		s.addMember(s.ensureGroup(append(path, name)), id)
+	}
+	delete(template, "groups")
+
+	return id
+}
+
+/* monitorAssignment turns a monitor of a template into an assignment, as
+ * listed by /devices/{id}/monitors/-. */
+func (s *Server) monitorAssignment(id, monitorType string, monitor Object) Object {
+	name, _ := monitor["name"].(string)
+	assignment := Object{"id": id, "type": monitorType, "monitorTypeName": name}
+
+	for _, entry := range s.library {
+		if entry.Type == monitorType && entry.Name == name {
+			assignment["monitorTypeClassId"] = entry.ClassID
+			assignment["monitorType"] = entry.ID
+		}
+	}
+
+	if monitorType == "active" {
+		active := Object{"argument": monitor["argument"], "comment": monitor["comment"]}
+		if monitor["isCritical"] == "true" {
+			/* WUG numbers the critical monitors from 1. */
+			order, _ := strconv.Atoi(fmt.Sprint(monitor["pollingOrder"]))
+			if order < 1 {
+				order = 1
+			}
+			active["criticalOrder"] = order
+		}
+		assignment["active"] = active
+	}
+
+	return assignment
+}
+
+/* templateMonitor renders a monitor assignment as in a template. */
+func templateMonitor(assignment Object) Object {
+	name, _ := assignment["monitorTypeName"].(string)
+	monitor := Object{"name": name}
+
+	if active, ok := assignment["active"].(Object); ok {
+		for _, key := range []string{"argument", "comment"} {
+			if value, ok := active[key].(string); ok && value != "" {
+				monitor[key] = value
+			}
+		}
+
+		order, _ := strconv.Atoi(fmt.Sprint(active["criticalOrder"]))
+		if order > 0 {
+			monitor["isCritical"] = "true"
+			monitor["pollingOrder"] = strconv.Itoa(order)
+		}
+	}
+
+	return monitor
+}
+
+/* ensureGroup returns the ID of the group at path, creating it and its
+ * missing ancestors. A missing top-level group is created without parent. */
+func (s *Server) ensureGroup(path []string) string {
+	parentID := ""
+
+	for _, name := range path {
+		found := ""
+		for _, id := range s.sortedGroupIDs() {
+			if group := s.groups[id]; group.ParentID == parentID && group.Name == name {
+				found = id
+				break
+			}
+		}
+
+		if found == "" {
+			found = s.newID()
+			s.groups[found] = &DeviceGroup{ID: found, ParentID: parentID, Name: name, Type: StaticGroup}
+		}
+
+		parentID = found
+	}
+
+	return parentID
+}
+
+/* deviceGroups renders the groups of a device as in a template. */
+func (s *Server) deviceGroups(deviceID string) []Object {
+	groups := make([]Object, 0)
+
+	for _, id := range s.sortedGroupIDs() {
+		if !s.members[id][deviceID] {
+			continue
+		}
+
+		path := s.groupPath(id)
+		groups = append(groups, Object{
+			"name":    path[len(path)-1],
+			"parents": path[:len(path)-1],
+		})
+	}
+
+	return groups
+}
+
+func (s *Server) removeDevice(id string) {
+	delete(s.devices, id)
+	delete(s.maintenance, MaintenanceDevice+"/"+id)
+
+	for _, members := range s.members {
+		delete(members, id)
+	}
+}
+
+func (s *Server) addMember(groupID, deviceID string) {
+	if s.members[groupID] == nil {
+		s.members[groupID] = make(map[string]bool)
+	}
+	s.members[groupID][deviceID] = true
+}
+
+func (s *Server) groupMembers(groupID string) []string {
+	ids := make([]string, 0, len(s.members[groupID]))
+	for id := range s.members[groupID] {
+		ids = append(ids, id)
+	}
+	sortIDs(ids)
+
+	return ids
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, Object{
+		"error": Object{"message": message},
+	})
+}
+
+func writeData(w http.ResponseWriter, data interface{}) {
+	writeJSON(w, http.StatusOK, Object{"data": data})
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	/* Keep escaped IDs in one segment, they are unescaped below. */
+	path := r.URL.EscapedPath()
+	if !strings.HasPrefix(path, BasePath+"/") {
+		writeError(w, http.StatusNotFound, "unknown path "+path)
+		return
+	}
+	path = strings.TrimPrefix(path, BasePath)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests[r.Method+" "+path]++
+
+	if f, ok := s.failures[r.Method+" "+path]; ok {
+		w.WriteHeader(f.status)
+		w.Write([]byte(f.body))
+		return
+	}
+
+	if path == "/token" {
+		s.serveToken(w, r)
+		return
+	}
+
+	if !s.authorized(r) {
+		writeError(w, http.StatusUnauthorized, "Authorization has been denied for this request.")
+		return
+	}
+
+	var body Object
+	if r.Body != nil {
+		raw, _ := ioutil.ReadAll(r.Body)
+		if len(raw) > 0 {
+			if err := json.Unmarshal(raw, &body); err != nil {
+				writeError(w, http.StatusBadRequest, "invalid JSON body: "+err.Error())
+				return
+			}
+		}
+	}
+
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, segment := range segments {
+		segments[i], _ = url.PathUnescape(segment)
+	}
+
+	if !s.route(w, r, segments, body) {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("no route for %s %s", r.Method, path))
+	}
+}
+
+// route dispatches an authenticated call, and returns false when no
+// handler matches.
+func (s *Server) route(w http.ResponseWriter, r *http.Request, segments []string, body Object) bool {
+	match := func(method string, pattern ...string) bool {
+		if r.Method != method || len(segments) != len(pattern) {
+			return false
+		}
+		for i, p := range pattern {
+			if p != "*" && p != segments[i] {
+				return false
+			}
+		}
+		return true
+	}
+
+	switch {
+	case match(http.MethodPatch, "devices", "-", "config", "template"):
+		s.applyTemplates(w, body)
+	case match(http.MethodGet, "devices", "*", "config", "template"):
+		s.getTemplate(w, segments[1])
+	case match(http.MethodGet, "devices", "-"):
+		s.listDevices(w, r.URL.Query())
+	case match(http.MethodDelete, "devices", "*"):
+		s.deleteDevice(w, segments[1])
+	case match(http.MethodPut, "devices", "*", "properties"):
+		s.updateDeviceProperties(w, segments[1], body)
+	case match(http.MethodGet, "devices", "*", "monitors", "-"):
+		s.listDeviceMonitors(w, segments[1], r.URL.Query())
+	case match(http.MethodPost, "devices", "*", "monitors", "-"):
+		s.addDeviceMonitor(w, segments[1], body)
+	case match(http.MethodGet, "devices", "*", "monitors", "*"):
+		s.getDeviceMonitor(w, segments[1], segments[3])
+	case match(http.MethodPut, "devices", "*", "monitors", "*"):
+		s.updateDeviceMonitor(w, segments[1], segments[3], body)
+	case match(http.MethodDelete, "devices", "*", "monitors", "*"):
+		s.deleteDeviceMonitor(w, segments[1], segments[3])
+	case match(http.MethodGet, "devices", "*", "config", "maintenance"):
+		s.getMaintenance(w, MaintenanceDevice, segments[1])
+	case match(http.MethodPut, "devices", "*", "config", "maintenance"):
+		s.setMaintenance(w, MaintenanceDevice, segments[1], body)
+	case match(http.MethodGet, "devices", "*", "config", "maintenance", "schedule"):
+		s.getMaintenanceSchedules(w, MaintenanceDevice, segments[1])
+	case match(http.MethodPut, "devices", "*", "config", "maintenance", "schedule"):
+		s.setMaintenanceSchedules(w, MaintenanceDevice, segments[1], body)
+	case match(http.MethodGet, "device-groups", "*", "config", "maintenance"):
+		s.getMaintenance(w, MaintenanceGroup, segments[1])
+	case match(http.MethodPut, "device-groups", "*", "config", "maintenance"):
+		s.setMaintenance(w, MaintenanceGroup, segments[1], body)
+	case match(http.MethodGet, "device-groups", "*", "config", "maintenance", "schedule"):
+		s.getMaintenanceSchedules(w, MaintenanceGroup, segments[1])
+	case match(http.MethodPut, "device-groups", "*", "config", "maintenance", "schedule"):
+		s.setMaintenanceSchedules(w, MaintenanceGroup, segments[1], body)
+	case match(http.MethodGet, "device-groups", "-"):
+		s.listDeviceGroups(w, r.URL.Query())
+	case match(http.MethodGet, "device-groups", "*", "devices", "-"):
+		s.listGroupDevices(w, segments[1], r.URL.Query())
+	case match(http.MethodPatch, "device-groups", "*", "devices", "-"):
+		s.updateGroupDevices(w, segments[1], body)
+	case match(http.MethodPost, "device-groups", "*", "newGroup"):
+		s.createDeviceGroup(w, segments[1], body)
+	case match(http.MethodGet, "device-groups", "*"):
+		s.getDeviceGroup(w, segments[1])
+	case match(http.MethodPut, "device-groups", "*"):
+		s.updateDeviceGroup(w, segments[1], body)
+	case match(http.MethodDelete, "device-groups", "*"):
+		s.deleteDeviceGroup(w, segments[1])
+	case match(http.MethodGet, "devices", "*", "interfaces", "-"):
+		s.listDeviceInterfaces(w, segments[1], r.URL.Query())
+	case match(http.MethodPost, "devices", "*", "interfaces", "-"):
+		s.addDeviceInterface(w, segments[1], body)
+	case match(http.MethodPut, "devices", "*", "interfaces", "*"):
+		s.updateDeviceInterface(w, segments[1], segments[3], body)
+	case match(http.MethodDelete, "devices", "*", "interfaces", "*"):
+		s.deleteDeviceInterface(w, segments[1], segments[3])
+	case match(http.MethodGet, "devices", "*", "attributes", "-"):
+		s.listDeviceAttributes(w, segments[1], r.URL.Query())
+	case match(http.MethodPost, "devices", "*", "attributes", "-"):
+		s.addDeviceAttribute(w, segments[1], body)
+	case match(http.MethodGet, "devices", "*", "attributes", "*"):
+		s.getDeviceAttribute(w, segments[1], segments[3])
+	case match(http.MethodPut, "devices", "*", "attributes", "*"):
+		s.updateDeviceAttribute(w, segments[1], segments[3], body)
+	case match(http.MethodDelete, "devices", "*", "attributes", "*"):
+		s.deleteDeviceAttribute(w, segments[1], segments[3])
+	case match(http.MethodGet, "devices", "*", "credentials", "-"):
+		s.listDeviceCredentials(w, segments[1], r.URL.Query())
+	case match(http.MethodPut, "devices", "*", "credentials", "*"):
+		s.assignDeviceCredential(w, segments[1], segments[3])
+	case match(http.MethodDelete, "devices", "*", "credentials", "*"):
+		s.deleteDeviceCredential(w, segments[1], segments[3])
+	case match(http.MethodGet, "monitors", "-"):
+		s.searchMonitors(w, r.URL.Query())
+	case match(http.MethodGet, "credentials", "-"):
+		s.listCredentials(w, r.URL.Query())
+	default:
+		return false
+	}
+
+	return true
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	expiry, ok := s.tokens[token]
+
+	return ok && time.Now().Before(expiry)
+}
+
+/* The provider posts a form-encoded body with a JSON content type, parse
+ * it whatever the header says. */
+func (s *Server) serveToken(w http.ResponseWriter, r *http.Request) {
+	raw, _ := ioutil.ReadAll(r.Body)
+	params, err := url.ParseQuery(string(raw))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, Object{"error": "invalid_request"})
+		return
+	}
+
+	switch params.Get("grant_type") {
+	case "password":
+		if params.Get("username") != User || params.Get("password") != Password {
+			writeJSON(w, http.StatusBadRequest, Object{"error": "invalid_grant", "error_description": "The user name or password is incorrect."})
+			return
+		}
+	case "refresh_token":
+		if !s.refreshTokens[params.Get("refresh_token")] {
+			writeJSON(w, http.StatusBadRequest, Object{"error": "invalid_grant"})
+			return
+		}
+		delete(s.refreshTokens, params.Get("refresh_token"))
+	default:
+		writeJSON(w, http.StatusBadRequest, Object{"error": "unsupported_grant_type"})
+		return
+	}
+
+	access := "access-" + s.newID()
+	refresh := "refresh-" + s.newID()
+	s.tokens[access] = time.Now().Add(s.TokenLifetime)
+	s.refreshTokens[refresh] = true
+
+	writeJSON(w, http.StatusOK, Object{
+		"access_token":  access,
+		"token_type":    "bearer",
+		"expires_in":    int(s.TokenLifetime.Seconds()),
+		"refresh_token": refresh,
+	})
+}
+
+func (s *Server) applyTemplates(w http.ResponseWriter, body Object) {
+	templates, ok := body["templates"].([]interface{})
+	if !ok {
+		writeError(w, http.StatusBadRequest, "templates is required")
+		return
+	}
+
+	idMap := make([]Object, 0, len(templates))
+	failed := make([]Object, 0)
+
+	for i, item := range templates {
+		template, ok := item.(Object)
+		templateID := strconv.Itoa(i)
+		if ok {
+			if id, set := template["templateId"]; set {
+				templateID = fmt.Sprint(id)
+			}
+		}
+
+		if !ok || template["displayName"] == nil {
+			failed = append(failed, Object{"templateId": templateID, "messages": []string{"displayName is required"}})
+			continue
+		}
+
+		if messages := s.unknownCredentials(template); len(messages) > 0 {
+			failed = append(failed, Object{"templateId": templateID, "messages": messages})
+			continue
+		}
+
+		delete(template, "templateId")
+		idMap = append(idMap, Object{
+			"templateId": templateID,
+			"resultId":   s.addDevice(template),
+		})
+	}
+
+	writeData(w, Object{
+		"idMap":  idMap,
+		"errors": failed,
+	})
+}
+
+func (s *Server) getTemplate(w http.ResponseWriter, id string) {
+	device, ok := s.devices[id]
+	if !ok {
+		/* WUG answers 200 with an empty template list for unknown IDs. */
+		writeData(w, Object{"deviceCount": 0, "templates": []Object{}})
+		return
+	}
+
+	template := Object{"templateId": device.ID}
+	for key, value := range device.Template {
+		template[key] = value
+	}
+	template["groups"] = s.deviceGroups(id)
+
+	interfaces := make([]Object, 0, len(device.Interfaces))
+	for _, iface := range sortedObjects(device.Interfaces) {
+		rendered := Object{}
+		for key, value := range iface {
+			if key != "id" {
+				rendered[key] = value
+			}
+		}
+		interfaces = append(interfaces, rendered)
+	}
+	template["interfaces"] = interfaces
+
+	credentials := make([]Object, 0, len(device.Credentials))
+	for _, credential := range sortedObjects(device.Credentials) {
+		credentials = append(credentials, Object{
+			"credentialType": credential["type"],
+			"credential":     credential["name"],
+		})
+	}
+	template["credentials"] = credentials
+
+	monitors := Object{"active": make([]Object, 0), "performance": make([]Object, 0)}
+	for _, assignment := range sortedObjects(device.Monitors) {
+		monitorType := strings.ToLower(fmt.Sprint(assignment["type"]))
+		if _, ok := monitors[monitorType]; !ok {
+			continue
+		}
+
+		monitor, ok := device.templateMonitors[fmt.Sprint(assignment["id"])]
+		if !ok {
+			monitor = templateMonitor(assignment)
+		}
+		monitors[monitorType] = append(monitors[monitorType].([]Object), monitor)
+	}
+	template["activeMonitors"] = monitors["active"]
+	template["performanceMonitors"] = monitors["performance"]
+
+	writeData(w, Object{"deviceCount": 1, "templates": []Object{template}})
+}
+
+func (s *Server) updateDeviceProperties(w http.ResponseWriter, id string, body Object) {
+	device, ok := s.devices[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "device "+id+" not found")
+		return
+	}
+
+	/* Properties share their names with template fields. */
+	for key, value := range body {
+		if value == "" {
+			delete(device.Template, key)
+		} else {
+			device.Template[key] = value
+		}
+	}
+
+	writeData(w, Object{"success": true})
+}
+
+func (s *Server) deleteDevice(w http.ResponseWriter, id string) {
+	if _, ok := s.devices[id]; !ok {
+		writeError(w, http.StatusNotFound, "device "+id+" not found")
+		return
+	}
+
+	s.removeDevice(id)
+	writeData(w, Object{"success": true})
+}
+
+func (s *Server) listDeviceMonitors(w http.ResponseWriter, deviceID string, query url.Values) {
+	device, ok := s.devices[deviceID]
+	if !ok {
+		writeError(w, http.StatusNotFound, "device "+deviceID+" not found")
+		return
+	}
+
+	monitors := sortedObjects(device.Monitors)
+	start, end, paging := s.page(query, len(monitors))
+
+	writeJSON(w, http.StatusOK, Object{
+		"paging": paging,
+		"data":   Object{"monitors": monitors[start:end]},
+	})
+}
+
+func (s *Server) addDeviceMonitor(w http.ResponseWriter, deviceID string, body Object) {
+	device, ok := s.devices[deviceID]
+	if !ok {
+		writeError(w, http.StatusNotFound, "device "+deviceID+" not found")
+		return
+	}
+
+	id := s.newID()
+	body["id"] = id
+	device.Monitors[id] = body
+
+	writeData(w, Object{
+		"idMap": []Object{{"templateId": "0", "resultId": id}},
+	})
+}
+
+func (s *Server) getDeviceMonitor(w http.ResponseWriter, deviceID, assignmentID string) {
+	device, ok := s.devices[deviceID]
+	if !ok {
+		writeError(w, http.StatusNotFound, "device "+deviceID+" not found")
+		return
+	}
+
+	monitor, ok := device.Monitors[assignmentID]
+	if !ok {
+		writeError(w, http.StatusNotFound, "monitor assignment "+assignmentID+" not found")
+		return
+	}
+
+	writeData(w, monitor)
+}
+
+func (s *Server) updateDeviceMonitor(w http.ResponseWriter, deviceID, assignmentID string, body Object) {
+	device, ok := s.devices[deviceID]
+	if !ok {
+		writeError(w, http.StatusNotFound, "device "+deviceID+" not found")
+		return
+	}
+
+	if _, ok := device.Monitors[assignmentID]; !ok {
+		writeError(w, http.StatusNotFound, "monitor assignment "+assignmentID+" not found")
+		return
+	}
+
+	body["id"] = assignmentID
+	device.Monitors[assignmentID] = body
+	delete(device.templateMonitors, assignmentID)
+
+	writeData(w, Object{"success": true})
+}
+
+func (s *Server) deleteDeviceMonitor(w http.ResponseWriter, deviceID, assignmentID string) {
+	device, ok := s.devices[deviceID]
+	if !ok {
+		writeError(w, http.StatusNotFound, "device "+deviceID+" not found")
+		return
+	}
+
+	if _, ok := device.Monitors[assignmentID]; !ok {
+		writeError(w, http.StatusNotFound, "monitor assignment "+assignmentID+" not found")
+		return
+	}
+
+	delete(device.Monitors, assignmentID)
+	delete(device.templateMonitors, assignmentID)
+	writeData(w, Object{"success": true})
+}
+
+/* addressTaken reports whether another interface of the device has this
+ * network address. */
+func addressTaken(device *Device, address, exceptID string) bool {
+	for id, iface := range device.Interfaces {
+		if id != exceptID && iface["networkAddress"] == address {
+			return true
+		}
+	}
+	return false
+}
+
+/* setDefaultInterface clears the default flag on every other interface. */
+func setDefaultInterface(device *Device, interfaceID string) {
+	for id, iface := range device.Interfaces {
+		iface["defaultInterface"] = id == interfaceID
+	}
+}
+
+func (s *Server) listDeviceInterfaces(w http.ResponseWriter, deviceID string, query url.Values) {
+	device, ok := s.devices[deviceID]
+	if !ok {
+		writeError(w, http.StatusNotFound, "device "+deviceID+" not found")
+		return
+	}
+
+	interfaces := sortedObjects(device.Interfaces)
+	start, end, paging := s.page(query, len(interfaces))
+
+	writeJSON(w, http.StatusOK, Object{
+		"paging": paging,
+		"data":   Object{"interfaces": interfaces[start:end]},
+	})
+}
+
+func (s *Server) addDeviceInterface(w http.ResponseWriter, deviceID string, body Object) {
+	device, ok := s.devices[deviceID]
+	if !ok {
+		writeError(w, http.StatusNotFound, "device "+deviceID+" not found")
+		return
+	}
+
+	address, _ := body["networkAddress"].(string)
+	if address == "" {
+		writeError(w, http.StatusBadRequest, "networkAddress is required")
+		return
+	}
+	if addressTaken(device, address, "") {
+		writeError(w, http.StatusBadRequest, "interface "+address+" already exists")
+		return
+	}
+
+	id := s.newID()
+	body["id"] = id
+	device.Interfaces[id] = body
+
+	if body["defaultInterface"] == true || len(device.Interfaces) == 1 {
+		setDefaultInterface(device, id)
+	}
+
+	writeData(w, Object{"interfaceId": id})
+}
+
+func (s *Server) updateDeviceInterface(w http.ResponseWriter, deviceID, interfaceID string, body Object) {
+	device, ok := s.devices[deviceID]
+	if !ok {
+		writeError(w, http.StatusNotFound, "device "+deviceID+" not found")
+		return
+	}
+
+	iface, ok := device.Interfaces[interfaceID]
+	if !ok {
+		writeError(w, http.StatusNotFound, "interface "+interfaceID+" not found")
+		return
+	}
+
+	if address, ok := body["networkAddress"].(string); ok && address != "" {
+		if addressTaken(device, address, interfaceID) {
+			writeError(w, http.StatusBadRequest, "interface "+address+" already exists")
+			return
+		}
+		iface["networkAddress"] = address
+	}
+	if name, ok := body["networkName"].(string); ok {
+		iface["networkName"] = name
+	}
+	if poll, ok := body["pollUsingNetworkName"].(bool); ok {
+		iface["pollUsingNetworkName"] = poll
+	}
+
+	/* A device always has a default interface, it can only be moved. */
+	if body["defaultInterface"] == true {
+		setDefaultInterface(device, interfaceID)
+	}
+
+	writeData(w, Object{"success": true})
+}
+
+func (s *Server) deleteDeviceInterface(w http.ResponseWriter, deviceID, interfaceID string) {
+	device, ok := s.devices[deviceID]
+	if !ok {
+		writeError(w, http.StatusNotFound, "device "+deviceID+" not found")
+		return
+	}
+
+	iface, ok := device.Interfaces[interfaceID]
+	if !ok {
+		writeError(w, http.StatusNotFound, "interface "+interfaceID+" not found")
+		return
+	}
+	if iface["defaultInterface"] == true {
+		writeError(w, http.StatusBadRequest, "the default interface cannot be removed")
+		return
+	}
+
+	delete(device.Interfaces, interfaceID)
+	writeData(w, Object{"success": true})
+}
+
+func (s *Server) listDeviceAttributes(w http.ResponseWriter, deviceID string, query url.Values) {
+	device, ok := s.devices[deviceID]
+	if !ok {
+		writeError(w, http.StatusNotFound, "device "+deviceID+" not found")
+		return
+	}
+
+	ids := make([]string, 0, len(device.Attributes))
+	for id := range device.Attributes {
+		ids = append(ids, id)
+	}
+	sortIDs(ids)
+
+	attributes := make([]Object, 0, len(ids))
+	for _, id := range ids {
+		attributes = append(attributes, device.Attributes[id])
+	}
+
+	start, end, paging := s.page(query, len(attributes))
+
+	writeJSON(w, http.StatusOK, Object{
+		"paging": paging,
+		"data":   attributes[start:end],
+	})
+}
+
+/* attributeNameTaken reports whether another attribute of the device has
+ * this name. */
+func attributeNameTaken(device *Device, name, exceptID string) bool {
+	for id, attribute := range device.Attributes {
+		if id != exceptID && attribute["name"] == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) addDeviceAttribute(w http.ResponseWriter, deviceID string, body Object) {
+	device, ok := s.devices[deviceID]
+	if !ok {
+		writeError(w, http.StatusNotFound, "device "+deviceID+" not found")
+		return
+	}
+
+	name, _ := body["name"].(string)
+	value, _ := body["value"].(string)
+	if name == "" {
+		writeError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+	if attributeNameTaken(device, name, "") {
+		writeError(w, http.StatusBadRequest, "attribute "+name+" already exists")
+		return
+	}
+
+	id := s.newID()
+	device.Attributes[id] = Object{"id": id, "name": name, "value": value}
+
+	writeData(w, Object{"attributeId": id})
+}
+
+func (s *Server) getDeviceAttribute(w http.ResponseWriter, deviceID, attributeID string) {
+	device, ok := s.devices[deviceID]
+	if !ok {
+		writeError(w, http.StatusNotFound, "device "+deviceID+" not found")
+		return
+	}
+
+	attribute, ok := device.Attributes[attributeID]
+	if !ok {
+		writeError(w, http.StatusNotFound, "attribute "+attributeID+" not found")
+		return
+	}
+
+	writeData(w, attribute)
+}
+
+func (s *Server) updateDeviceAttribute(w http.ResponseWriter, deviceID, attributeID string, body Object) {
+	device, ok := s.devices[deviceID]
+	if !ok {
+		writeError(w, http.StatusNotFound, "device "+deviceID+" not found")
+		return
+	}
+
+	attribute, ok := device.Attributes[attributeID]
+	if !ok {
+		writeError(w, http.StatusNotFound, "attribute "+attributeID+" not found")
+		return
+	}
+
+	if name, ok := body["name"].(string); ok && name != "" {
+		if attributeNameTaken(device, name, attributeID) {
+			writeError(w, http.StatusBadRequest, "attribute "+name+" already exists")
+			return
+		}
+		attribute["name"] = name
+	}
+	if value, ok := body["value"].(string); ok {
+		attribute["value"] = value
+	}
+
+	writeData(w, Object{"success": true})
+}
+
+func (s *Server) deleteDeviceAttribute(w http.ResponseWriter, deviceID, attributeID string) {
+	device, ok := s.devices[deviceID]
+	if !ok {
+		writeError(w, http.StatusNotFound, "device "+deviceID+" not found")
+		return
+	}
+
+	if _, ok := device.Attributes[attributeID]; !ok {
+		writeError(w, http.StatusNotFound, "attribute "+attributeID+" not found")
+		return
+	}
+
+	delete(device.Attributes, attributeID)
+	writeData(w, Object{"success": true})
+}
+
+func (s *Server) searchMonitors(w http.ResponseWriter, query url.Values) {
+	monitorType := query.Get("type")
+	search := strings.ToLower(query.Get("search"))
+
+	entries := make([]Object, 0)
+
+	for _, monitor := range s.library {
+		if monitorType != "" && monitor.Type != monitorType {
+			continue
+		}
+		if !strings.Contains(strings.ToLower(monitor.Name), search) {
+			continue
+		}
+
+		entry := Object{
+			"monitorId": monitor.ID,
+			"id":        monitor.ID,
+			"name":      monitor.Name,
+			"monitorTypeInfo": Object{
+				"classId":  monitor.ClassID,
+				"baseType": monitor.Type,
+			},
+		}
+
+		entries = append(entries, entry)
+	}
+
+	start, end, paging := s.page(query, len(entries))
+
+	active := make([]Object, 0)
+	performance := make([]Object, 0)
+	for _, entry := range entries[start:end] {
+		if entry["monitorTypeInfo"].(Object)["baseType"] == "performance" {
+			performance = append(performance, entry)
+		} else {
+			active = append(active, entry)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, Object{
+		"paging": paging,
+		"data": Object{
+			"activeMonitors":      active,
+			"performanceMonitors": performance,
+		},
+	})
+}
+
+/* page slices items according to the limit and pageId query parameters,
+ * and returns the paging envelope. pageId is the offset of the page. */
+func (s *Server) page(query url.Values, count int) (int, int, Object) {
+	limit := s.PageSize
+	if l, err := strconv.Atoi(query.Get("limit")); err == nil && l > 0 {
+		limit = l
+	}
+
+	start, _ := strconv.Atoi(query.Get("pageId"))
+	if start < 0 || start > count {
+		start = count
+	}
+
+	end := start + limit
+	if end > count {
+		end = count
+	}
+
+	paging := Object{"size": end - start}
+	if end < count {
+		paging["nextPageId"] = strconv.Itoa(end)
+	}
+
+	return start, end, paging
+}
+
+func groupObject(group *DeviceGroup) Object {
+	object := Object{
+		"id":          group.ID,
+		"name":        group.Name,
+		"description": group.Description,
+		"groupType":   group.Type,
+	}
+	if group.Type == DynamicGroup {
+		object["filter"] = group.Filter
+	}
+	if group.ParentID != "" {
+		object["parentGroupId"] = group.ParentID
+	}
+
+	return object
+}
+
+/* sortedObjects returns the values of a map keyed by numeric IDs, in
+ * creation order. */
+func sortedObjects(objects map[string]Object) []Object {
+	ids := make([]string, 0, len(objects))
+	for id := range objects {
+		ids = append(ids, id)
+	}
+	sortIDs(ids)
+
+	sorted := make([]Object, 0, len(ids))
+	for _, id := range ids {
+		sorted = append(sorted, objects[id])
+	}
+
+	return sorted
+}
+
+/* sortIDs sorts numeric IDs in creation order. */
+func sortIDs(ids []string) {
+	sort.Slice(ids, func(i, j int) bool {
+		a, _ := strconv.Atoi(ids[i])
+		b, _ := strconv.Atoi(ids[j])
+		return a < b
+	})
+}
+
+func (s *Server) sortedGroupIDs() []string {
+	ids := make([]string, 0, len(s.groups))
+	for id := range s.groups {
+		ids = append(ids, id)
+	}
+	sortIDs(ids)
+
+	return ids
+}
+
+/* groupPath returns the names of a group and its ancestors, from the root
+ * group down. */
+func (s *Server) groupPath(id string) []string {
+	var path []string
+
+	for group, ok := s.groups[id]; ok; group, ok = s.groups[group.ParentID] {
+		path = append([]string{group.Name}, path...)
+	}
+
+	return path
+}
+
+func (s *Server) findGroup(path []string) (string, bool) {
+	for id := range s.groups {
+		if strings.Join(s.groupPath(id), "/") == strings.Join(path, "/") {
+			return id, true
+		}
+	}
+
+	return "", false
+}
+
+/* deviceSummary renders a device as listed in a group. */
+func deviceSummary(device *Device) Object {
+	summary := Object{
+		"id":         device.ID,
+		"name":       device.Template["displayName"],
+		"role":       device.Template["primaryRole"],
+		"deviceType": device.Template["deviceType"],
+		"brand":      device.Template["brand"],
+		"os":         device.Template["os"],
+	}
+
+	for _, iface := range device.Interfaces {
+		if len(device.Interfaces) == 1 || iface["defaultInterface"] == true {
+			summary["networkAddress"] = iface["networkAddress"]
+			summary["hostName"] = iface["networkName"]
+		}
+	}
+
+	return summary
+}
+
+/* matchSearch reports whether the display name, network address or host
+ * name of a device summary contains search, ignoring case. */
+func matchSearch(summary Object, search string) bool {
+	for _, key := range []string{"name", "networkAddress", "hostName"} {
+		if value, _ := summary[key].(string); strings.Contains(strings.ToLower(value), strings.ToLower(search)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+/* siblingExists reports whether parentID already has a child named name,
+ * other than the group exceptID. */
+func (s *Server) siblingExists(parentID, name, exceptID string) bool {
+	for id, group := range s.groups {
+		if id != exceptID && group.ParentID == parentID && group.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) deleteGroupTree(id string) {
+	for childID, group := range s.groups {
+		if group.ParentID == id {
+			s.deleteGroupTree(childID)
+		}
+	}
+	delete(s.groups, id)
+	delete(s.members, id)
+	delete(s.maintenance, MaintenanceGroup+"/"+id)
+}
+
+func (s *Server) listDeviceGroups(w http.ResponseWriter, query url.Values) {
+	search := strings.ToLower(query.Get("search"))
+
+	groups := make([]Object, 0)
+	for _, id := range s.sortedGroupIDs() {
+		group := s.groups[id]
+		if strings.Contains(strings.ToLower(group.Name), search) {
+			groups = append(groups, groupObject(group))
+		}
+	}
+
+	start, end, paging := s.page(query, len(groups))
+
+	writeJSON(w, http.StatusOK, Object{
+		"paging": paging,
+		"data":   Object{"groups": groups[start:end]},
+	})
+}
+
+/* listDevices searches the display name, network address and host name of
+ * every device. */
+func (s *Server) listDevices(w http.ResponseWriter, query url.Values) {
+	search := query.Get("search")
+
+	ids := make([]string, 0, len(s.devices))
+	for id := range s.devices {
+		ids = append(ids, id)
+	}
+	sortIDs(ids)
+
+	devices := make([]Object, 0)
+	for _, id := range ids {
+		if summary := deviceSummary(s.devices[id]); matchSearch(summary, search) {
+			devices = append(devices, summary)
+		}
+	}
+
+	start, end, paging := s.page(query, len(devices))
+
+	writeJSON(w, http.StatusOK, Object{
+		"paging": paging,
+		"data":   Object{"devices": devices[start:end]},
+	})
+}
+
+/* groupDeviceIDs returns the members of a group, or the devices matching
+ * its filter for a dynamic group, and the ones of its subgroups when
+ * recursive is set. */
+func (s *Server) groupDeviceIDs(groupID string, recursive bool, seen map[string]bool) {
+	group := s.groups[groupID]
+	if group.Type == DynamicGroup {
+		for id, device := range s.devices {
+			if ok, _ := MatchFilter(group.Filter, deviceSummary(device)); ok {
+				seen[id] = true
+			}
+		}
+	} else {
+		for id := range s.members[groupID] {
+			seen[id] = true
+		}
+	}
+
+	if !recursive {
+		return
+	}
+
+	for childID, child := range s.groups {
+		if child.ParentID == groupID {
+			s.groupDeviceIDs(childID, true, seen)
+		}
+	}
+}
+
+func (s *Server) listGroupDevices(w http.ResponseWriter, groupID string, query url.Values) {
+	if _, ok := s.groups[groupID]; !ok {
+		writeError(w, http.StatusNotFound, "device group "+groupID+" not found")
+		return
+	}
+
+	seen := make(map[string]bool)
+	s.groupDeviceIDs(groupID, query.Get("recursive") == "true", seen)
+
+	ids := make([]string, 0, len(seen))
+	for id := range seen {
+		ids = append(ids, id)
+	}
+	sortIDs(ids)
+
+	devices := make([]Object, 0)
+	for _, id := range ids {
+		if summary := deviceSummary(s.devices[id]); matchSearch(summary, query.Get("search")) {
+			devices = append(devices, summary)
+		}
+	}
+
+	start, end, paging := s.page(query, len(devices))
+
+	writeJSON(w, http.StatusOK, Object{
+		"paging": paging,
+		"data":   Object{"devices": devices[start:end]},
+	})
+}
+
+func (s *Server) updateGroupDevices(w http.ResponseWriter, groupID string, body Object) {
+	group, ok := s.groups[groupID]
+	if !ok {
+		writeError(w, http.StatusNotFound, "device group "+groupID+" not found")
+		return
+	}
+	if group.Type == DynamicGroup {
+		writeError(w, http.StatusBadRequest, "the devices of a dynamic group are set by its filter")
+		return
+	}
+
+	add, _ := body["add"].([]interface{})
+	remove, _ := body["remove"].([]interface{})
+
+	for _, id := range append(add, remove...) {
+		if _, ok := s.devices[fmt.Sprint(id)]; !ok {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("device %v not found", id))
+			return
+		}
+	}
+
+	for _, id := range add {
+		s.addMember(groupID, fmt.Sprint(id))
+	}
+	for _, id := range remove {
+		delete(s.members[groupID], fmt.Sprint(id))
+	}
+
+	writeData(w, Object{"success": true})
+}
+
+func (s *Server) createDeviceGroup(w http.ResponseWriter, parentID string, body Object) {
+	if _, ok := s.groups[parentID]; !ok {
+		writeError(w, http.StatusNotFound, "device group "+parentID+" not found")
+		return
+	}
+
+	name, _ := body["name"].(string)
+	if name == "" {
+		writeError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+	if s.siblingExists(parentID, name, "") {
+		writeError(w, http.StatusBadRequest, "a group named "+name+" already exists")
+		return
+	}
+
+	group := &DeviceGroup{ParentID: parentID, Name: name, Type: StaticGroup}
+	group.Description, _ = body["description"].(string)
+
+	if body["groupType"] == DynamicGroup {
+		group.Type = DynamicGroup
+		group.Filter, _ = body["filter"].(string)
+		if _, err := MatchFilter(group.Filter, Object{}); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid filter: "+err.Error())
+			return
+		}
+	}
+
+	group.ID = s.newID()
+	s.groups[group.ID] = group
+
+	writeData(w, Object{"groupId": group.ID, "success": true})
+}
+
+func (s *Server) getDeviceGroup(w http.ResponseWriter, id string) {
+	group, ok := s.groups[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "device group "+id+" not found")
+		return
+	}
+
+	writeData(w, groupObject(group))
+}
+
+func (s *Server) updateDeviceGroup(w http.ResponseWriter, id string, body Object) {
+	group, ok := s.groups[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "device group "+id+" not found")
+		return
+	}
+
+	updated := *group
+	if name, ok := body["name"].(string); ok && name != "" {
+		updated.Name = name
+	}
+	if description, ok := body["description"].(string); ok {
+		updated.Description = description
+	}
+	if parentID, ok := body["parentGroupId"].(string); ok && parentID != "" {
+		updated.ParentID = parentID
+	}
+	/* The group is replaced, a dynamic group sent without filter loses it. */
+	if filter, ok := body["filter"].(string); ok && updated.Type == DynamicGroup {
+		if _, err := MatchFilter(filter, Object{}); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid filter: "+err.Error())
+			return
+		}
+		updated.Filter = filter
+	} else {
+		updated.Filter = ""
+	}
+
+	if updated.ParentID != group.ParentID {
+		if id == RootGroupID {
+			writeError(w, http.StatusBadRequest, "the root group cannot be moved")
+			return
+		}
+		/* Walk up from the new parent, the group must not be found. */
+		for ancestor := updated.ParentID; ancestor != ""; {
+			parent, ok := s.groups[ancestor]
+			if !ok {
+				writeError(w, http.StatusNotFound, "device group "+ancestor+" not found")
+				return
+			}
+			if parent.ID == id {
+				writeError(w, http.StatusBadRequest, "a group cannot be moved under itself")
+				return
+			}
+			ancestor = parent.ParentID
+		}
+	}
+
+	if s.siblingExists(updated.ParentID, updated.Name, id) {
+		writeError(w, http.StatusBadRequest, "a group named "+updated.Name+" already exists")
+		return
+	}
+
+	*group = updated
+	writeData(w, Object{"success": true})
+}
+
+func (s *Server) deleteDeviceGroup(w http.ResponseWriter, id string) {
+	if _, ok := s.groups[id]; !ok {
+		writeError(w, http.StatusNotFound, "device group "+id+" not found")
+		return
+	}
+	if id == RootGroupID {
+		writeError(w, http.StatusBadRequest, "the root group cannot be deleted")
+		return
+	}
+
+	s.deleteGroupTree(id)
+	writeData(w, Object{"success": true})
+}